@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
 	"github.com/Azure/draft/pkg/reporeader"
 	"github.com/Azure/draft/pkg/reporeader/readers"
@@ -16,15 +17,21 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/dockerfile"
 	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/envfile"
 	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/k8svalidation"
 	"github.com/Azure/draft/pkg/languages"
 	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/policy"
 	"github.com/Azure/draft/pkg/prompts"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/variables"
 	"github.com/Azure/draft/template"
 )
 
@@ -34,6 +41,10 @@ var ErrNoLanguageDetected = errors.New("no supported languages were detected")
 var flagVariablesMap = make(map[string]string)
 
 const LANGUAGE_VARIABLE = "LANGUAGE"
+const DOCKERFILE_PROFILE_VARIABLE = "DOCKERFILE_PROFILE"
+const APPNAME_VARIABLE = "APPNAME"
+const RUNASNONROOT_VARIABLE = "RUNASNONROOT"
+const RUNASUSER_VARIABLE = "RUNASUSER"
 const TWO_SPACES = "  "
 
 // Flag defaults
@@ -45,11 +56,24 @@ type createCmd struct {
 	lang       string
 	dest       string
 	deployType string
+	profile    string
 
 	dockerfileOnly    bool
 	deploymentOnly    bool
 	skipFileDetection bool
+	skipEnvFile       bool
+	skipValidation    bool
 	flagVariables     []string
+	variableFile      string
+	environments      []string
+	policyDir         string
+	policyFailOn      string
+
+	// variables are the values resolved so far, shared by the Dockerfile and deployment phases so that
+	// variables with the same name are only asked for once
+	variables map[string]string
+	// dockerfile is the content of the Dockerfile generated in this run, which may not be written yet
+	dockerfile []byte
 
 	createConfigPath string
 	createConfig     *CreateConfig
@@ -79,14 +103,21 @@ func newCreateCmd() *cobra.Command {
 	f := cmd.Flags()
 
 	f.StringVarP(&cc.createConfigPath, "create-config", "c", emptyDefaultFlagValue, "specify the path to the configuration file")
-	f.StringVarP(&cc.appName, "app", "a", emptyDefaultFlagValue, "specify the name of the helm release")
+	f.StringVarP(&cc.appName, "app", "a", emptyDefaultFlagValue, "specify the name of the application, used for the APPNAME variable")
 	f.StringVarP(&cc.lang, "language", "l", emptyDefaultFlagValue, "specify the language used to create the Kubernetes deployment")
 	f.StringVarP(&cc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
 	f.StringVarP(&cc.deployType, "deploy-type", "", emptyDefaultFlagValue, "specify deployement type (eg. helm, kustomize, manifests)")
+	f.StringVar(&cc.profile, "profile", emptyDefaultFlagValue, "specify the Dockerfile profile to generate (eg. default, hardened)")
 	f.BoolVar(&cc.dockerfileOnly, "dockerfile-only", false, "only create Dockerfile in the project directory")
 	f.BoolVar(&cc.deploymentOnly, "deployment-only", false, "only create deployment files in the project directory")
 	f.BoolVar(&cc.skipFileDetection, "skip-file-detection", false, "skip file detection step")
-	f.StringArrayVarP(&cc.flagVariables, "variable", "", []string{}, "pass additional variables using repeated --variable flag")
+	f.BoolVar(&cc.skipEnvFile, "skip-env-file", false, "skip generating a ConfigMap and Secret from the .env file")
+	f.BoolVar(&cc.skipValidation, "skip-validation", false, "skip validating the generated Kubernetes resources against the Kubernetes schemas")
+	f.StringArrayVarP(&cc.flagVariables, "variable", "", []string{}, "pass additional variables using repeated --variable flag, read a value from a file with --variable NAME=@path")
+	f.StringVar(&cc.variableFile, "variable-file", emptyDefaultFlagValue, "specify a yaml or .env file of variables, overridden by DRAFT_VAR_ environment variables and --variable flags")
+	f.StringSliceVar(&cc.environments, "environments", []string{}, "specify the environments to create kustomize overlays for (eg. dev,staging,prod)")
+	f.StringVar(&cc.policyDir, "policy", emptyDefaultFlagValue, "check the generated files against the built-in policy rules and the rules in this directory before writing them")
+	f.StringVar(&cc.policyFailOn, "policy-fail-on", string(policy.SeverityError), "specify the lowest policy violation severity that stops the files from being written (info, warning, error)")
 
 	return cmd
 }
@@ -100,8 +131,8 @@ func (cc *createCmd) initConfig() error {
 		}
 
 		var cfg CreateConfig
-		if err = yaml.Unmarshal(configBytes, &cfg); err != nil {
-			return err
+		if err = config.DecodeStrict(configBytes, &cfg); err != nil {
+			return fmt.Errorf("%s: %w", cc.createConfigPath, err)
 		}
 		cc.createConfig = &cfg
 		return nil
@@ -116,13 +147,17 @@ func (cc *createCmd) initConfig() error {
 func (cc *createCmd) run() error {
 	log.Debugf("config: %s", cc.createConfigPath)
 
-	for _, flagVar := range cc.flagVariables {
-		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
-		if !ok {
-			return fmt.Errorf("invalid variable format: %s", flagVar)
-		}
-		flagVariablesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
+	vars, err := variables.Load(cc.flagVariables, cc.variableFile)
+	if err != nil {
+		return err
+	}
+	maps.Copy(flagVariablesMap, vars)
+
+	cc.initVariables()
+
+	if cc.profile != "" {
+		flagVariablesMap[DOCKERFILE_PROFILE_VARIABLE] = cc.profile
+		log.Debugf("flag variable %s=%s", DOCKERFILE_PROFILE_VARIABLE, cc.profile)
 	}
 
 	var dryRunRecorder *dryrunpkg.DryRunRecorder
@@ -135,12 +170,25 @@ func (cc *createCmd) run() error {
 	}
 	cc.repoReader = &readers.LocalFSReader{}
 
+	// with a policy, render the files in memory first, so nothing is written if they violate it
+	outputWriter := cc.templateWriter
+	var policyFiles *writers.FileMapWriter
+	if cc.policyDir != "" {
+		policyFiles = &writers.FileMapWriter{}
+		cc.templateWriter = policyFiles
+	}
+
 	detectedLangDraftConfig, languageName, err := cc.detectLanguage()
 	if err != nil {
 		return err
 	}
 
 	err = cc.createFiles(detectedLangDraftConfig, languageName)
+	if err == nil && policyFiles != nil {
+		if err = cc.checkPolicy(policyFiles.FileMap); err == nil {
+			err = writers.WriteFileMap(policyFiles.FileMap, outputWriter)
+		}
+	}
 	if dryRun {
 		cc.templateVariableRecorder.Record(LANGUAGE_VARIABLE, languageName)
 		dryRunText, err := json.MarshalIndent(dryRunRecorder.DryRunInfo, "", TWO_SPACES)
@@ -239,7 +287,7 @@ func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
 	for _, lang := range langs {
 		detectedLang := linguist.Alias(lang)
 		log.Infof("--> Draft detected %s (%f%%)\n", detectedLang.Language, detectedLang.Percent)
-		lowerLang := strings.ToLower(detectedLang.Language)
+		lowerLang := languages.RefineLanguage(strings.ToLower(detectedLang.Language), cc.repoReader)
 		if cc.supportedLangs.ContainsLanguage(lowerLang) {
 			if lowerLang == "go" && hasGo && hasGoMod {
 				log.Debug("detected go and go module")
@@ -265,32 +313,20 @@ func (cc *createCmd) generateDockerfile(langConfig *config.DraftConfig, lowerLan
 		return err
 	}
 
-	// Check for existing duplicate defualts
-	for k, v := range extractedValues {
-		variableExists := false
-		for i, varD := range langConfig.VariableDefaults {
-			if k == varD.Name {
-				variableExists = true
-				langConfig.VariableDefaults[i].Value = v
-				break
-			}
-		}
-		if !variableExists {
-			langConfig.VariableDefaults = append(langConfig.VariableDefaults, config.BuilderVarDefault{
-				Name:  k,
-				Value: v,
-			})
-		}
-	}
+	langConfig.SetVariableDefaults(extractedValues)
 
 	var inputs map[string]string
 	if cc.createConfig.LanguageVariables == nil {
-		inputs, err = prompts.RunPromptsFromConfigWithSkips(langConfig, maps.Keys(flagVariablesMap))
+		knownInputs, err := cc.dockerfileKnownInputs(langConfig, lowerLang)
+		if err != nil {
+			return err
+		}
+		inputs, err = prompts.RunPromptsFromConfigWithInputs(langConfig, knownInputs)
 		if err != nil {
 			return err
 		}
 	} else {
-		inputs, err = validateConfigInputsToPrompts(langConfig.Variables, cc.createConfig.LanguageVariables, langConfig.VariableDefaults)
+		inputs, err = validateConfigInputsToPrompts(langConfig.Variables, cc.withSharedInputs(langConfig, cc.createConfig.LanguageVariables), langConfig.VariableDefaults)
 		if err != nil {
 			return err
 		}
@@ -303,15 +339,162 @@ func (cc *createCmd) generateDockerfile(langConfig *config.DraftConfig, lowerLan
 	}
 
 	maps.Copy(inputs, flagVariablesMap)
+	cc.shareVariables(inputs)
 
-	if err = cc.supportedLangs.CreateDockerfileForLanguage(lowerLang, inputs, cc.templateWriter); err != nil {
+	recorder := &dockerfileRecorder{TemplateWriter: cc.templateWriter}
+	dockerfileWriter := &languages.DockerignoreWriter{TemplateWriter: recorder, RepoReader: cc.repoReader}
+	if err = cc.supportedLangs.CreateDockerfileForLanguage(lowerLang, inputs, dockerfileWriter); err != nil {
 		return fmt.Errorf("there was an error when creating the Dockerfile for language %s: %w", cc.createConfig.LanguageType, err)
 	}
+	cc.dockerfile = recorder.dockerfile
 
 	log.Info("--> Creating Dockerfile...\n")
 	return err
 }
 
+// dockerfileKnownInputs returns the known inputs of the language, along with the defaults of the variables the
+// generated files don't use, such as the VERSION of the default Dockerfile when the hardened profile is generated,
+// so that they aren't prompted for
+func (cc *createCmd) dockerfileKnownInputs(langConfig *config.DraftConfig, lowerLang string) (map[string]string, error) {
+	knownInputs := cc.knownInputs(langConfig)
+	defaultInputs, err := config.ResolveVariableDefaults(langConfig.VariableDefaults, knownInputs)
+	if err != nil {
+		return nil, err
+	}
+	unused, err := cc.supportedLangs.UnusedVariables(lowerLang, defaultInputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unused {
+		if _, ok := knownInputs[name]; !ok {
+			log.Debugf("not prompting for %s, which the generated files don't use", name)
+			knownInputs[name] = defaultInputs[name]
+		}
+	}
+	return knownInputs, nil
+}
+
+// readEnvFile returns the template variables for the ConfigMap and Secret generated from the env file of the
+// destination, or nil if it doesn't have one
+func (cc *createCmd) readEnvFile() (map[string]string, error) {
+	if cc.repoReader == nil {
+		return nil, nil
+	}
+	fileName := envfile.Find(cc.repoReader, cc.dest)
+	if fileName == "" {
+		return nil, nil
+	}
+
+	log.Infof("--> Found %s, generating a ConfigMap and Secret from its variables...", fileName)
+	envVars, err := envfile.Read(cc.repoReader, cc.dest)
+	if err != nil {
+		return nil, err
+	}
+	return envfile.ToTemplateVariables(envVars)
+}
+
+// initVariables starts the shared variables with the values set by flags, such as APPNAME from --app
+func (cc *createCmd) initVariables() {
+	cc.variables = make(map[string]string)
+	if cc.appName != "" {
+		cc.variables[APPNAME_VARIABLE] = cc.appName
+		log.Debugf("flag variable %s=%s", APPNAME_VARIABLE, cc.appName)
+	}
+}
+
+// knownInputs returns the values of the config's variables resolved in earlier phases, and the flag variables
+func (cc *createCmd) knownInputs(draftConfig *config.DraftConfig) map[string]string {
+	known := make(map[string]string)
+	for _, variable := range draftConfig.Variables {
+		if value, ok := cc.variables[variable.Name]; ok {
+			known[variable.Name] = value
+		}
+	}
+	maps.Copy(known, flagVariablesMap)
+	return known
+}
+
+// withSharedInputs returns the config file inputs of a phase, preceded by the values of the config's variables
+// resolved in earlier phases, so that values set in the config file take precedence
+func (cc *createCmd) withSharedInputs(draftConfig *config.DraftConfig, provided []UserInputs) []UserInputs {
+	var inputs []UserInputs
+	for _, name := range sortedKeys(cc.knownInputs(draftConfig)) {
+		if _, isFlag := flagVariablesMap[name]; !isFlag {
+			inputs = append(inputs, UserInputs{Name: name, Value: cc.variables[name]})
+		}
+	}
+	return append(inputs, provided...)
+}
+
+// shareVariables makes the resolved values of a phase available to the later phases
+func (cc *createCmd) shareVariables(inputs map[string]string) {
+	if cc.variables == nil {
+		cc.variables = make(map[string]string)
+	}
+	maps.Copy(cc.variables, inputs)
+}
+
+// dockerfileDefaults returns defaults for the deployment variables read from the Dockerfile generated in the same
+// run or else the existing Dockerfile in the destination, so the deployment sends traffic to the port the image
+// listens on and runs as the image's non-root USER. A Dockerfile generated in the same run shares its PORT
+// directly. An image that explicitly runs as root isn't required to run as non-root.
+func (cc *createCmd) dockerfileDefaults() map[string]string {
+	defaults := make(map[string]string)
+
+	content := cc.dockerfile
+	dockerfilePath := filepath.Join(cc.dest, "Dockerfile")
+	if content == nil && cc.repoReader != nil && cc.repoReader.Exists(dockerfilePath) {
+		var err error
+		if content, err = cc.repoReader.ReadFile(dockerfilePath); err != nil {
+			log.Debugf("not reading deployment defaults from %s: %s", dockerfilePath, err)
+			return defaults
+		}
+	}
+	if content == nil {
+		return defaults
+	}
+	parsed := dockerfile.Parse(content)
+
+	switch user := parsed.User(); {
+	case user == "root" || user == "0":
+		log.Debugf("the Dockerfile runs as root, not requiring the deployment to run as non-root")
+		defaults[RUNASNONROOT_VARIABLE] = "false"
+		defaults[RUNASUSER_VARIABLE] = "0"
+	case isUID(user):
+		log.Debugf("using the Dockerfile user %s as the default deployment user", user)
+		defaults[RUNASUSER_VARIABLE] = user
+	}
+
+	if _, ok := cc.variables["PORT"]; ok {
+		return defaults
+	}
+	if port := parsed.Port(); port != "" {
+		log.Debugf("using the Dockerfile port %s as the default deployment port", port)
+		defaults["PORT"] = port
+	}
+	return defaults
+}
+
+// isUID returns whether user is a numeric uid, which Kubernetes can verify isn't root, rather than a user name
+func isUID(user string) bool {
+	_, err := strconv.ParseUint(user, 10, 32)
+	return err == nil
+}
+
+// dockerfileRecorder passes the files it writes through to the wrapped TemplateWriter, keeping the content of
+// the Dockerfile so the deployment defaults can be read from it before it is written
+type dockerfileRecorder struct {
+	templatewriter.TemplateWriter
+	dockerfile []byte
+}
+
+func (w *dockerfileRecorder) WriteFile(filePath string, data []byte) error {
+	if filepath.Base(filePath) == "Dockerfile" {
+		w.dockerfile = data
+	}
+	return w.TemplateWriter.WriteFile(filePath, data)
+}
+
 func (cc *createCmd) createDeployment() error {
 	log.Info("--- Deployment File Creation ---")
 	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, cc.dest)
@@ -328,7 +511,8 @@ func (cc *createCmd) createDeployment() error {
 		if deployConfig == nil {
 			return errors.New("invalid deployment type")
 		}
-		customInputs, err = validateConfigInputsToPrompts(deployConfig.Variables, cc.createConfig.DeployVariables, deployConfig.VariableDefaults)
+		deployConfig.SetVariableDefaults(cc.dockerfileDefaults())
+		customInputs, err = validateConfigInputsToPrompts(deployConfig.Variables, cc.withSharedInputs(deployConfig, cc.createConfig.DeployVariables), deployConfig.VariableDefaults)
 		if err != nil {
 			return err
 		}
@@ -337,7 +521,7 @@ func (cc *createCmd) createDeployment() error {
 		if cc.deployType == "" {
 			selection := &promptui.Select{
 				Label: "Select k8s Deployment Type",
-				Items: []string{"helm", "kustomize", "manifests"},
+				Items: backends.DeployTypes(),
 			}
 
 			_, deployType, err = selection.Run()
@@ -352,13 +536,23 @@ func (cc *createCmd) createDeployment() error {
 		if err != nil {
 			return err
 		}
-		customInputs, err = prompts.RunPromptsFromConfigWithSkips(deployConfig, maps.Keys(flagVariablesMap))
+		deployConfig.SetVariableDefaults(cc.dockerfileDefaults())
+		customInputs, err = prompts.RunPromptsFromConfigWithInputs(deployConfig, cc.knownInputs(deployConfig))
 		if err != nil {
 			return err
 		}
 	}
 
+	if !cc.skipEnvFile {
+		envVariables, err := cc.readEnvFile()
+		if err != nil {
+			return err
+		}
+		maps.Copy(customInputs, envVariables)
+	}
+
 	maps.Copy(customInputs, flagVariablesMap)
+	cc.shareVariables(customInputs)
 
 	if cc.templateVariableRecorder != nil {
 		for k, v := range customInputs {
@@ -368,7 +562,90 @@ func (cc *createCmd) createDeployment() error {
 
 	log.Infof("--> Creating %s Kubernetes resources...\n", deployType)
 
-	return d.CopyDeploymentFiles(deployType, customInputs, cc.templateWriter)
+	environments := cc.createConfig.Environments
+	if len(cc.environments) > 0 {
+		environments = cc.environments
+	}
+
+	if cc.skipValidation {
+		return d.CopyDeploymentFiles(deployType, environments, customInputs, cc.templateWriter)
+	}
+
+	// render the files in memory first, so nothing is written if the generated resources are invalid
+	renderedFiles := &writers.FileMapWriter{}
+	if err := d.CopyDeploymentFiles(deployType, environments, customInputs, renderedFiles); err != nil {
+		return err
+	}
+	if err := cc.validateDeploymentFiles(deployType, renderedFiles.FileMap); err != nil {
+		return err
+	}
+	return writers.WriteFileMap(renderedFiles.FileMap, cc.templateWriter)
+}
+
+// validateDeploymentFiles validates the resources of the rendered deployment files against the bundled Kubernetes schemas
+func (cc *createCmd) validateDeploymentFiles(deployType string, fileMap map[string][]byte) error {
+	log.Infof("--> Validating %s Kubernetes resources against the Kubernetes %s schemas...\n", deployType, k8svalidation.KubernetesVersion())
+	files, err := cc.relativeFileMap(fileMap)
+	if err != nil {
+		return err
+	}
+
+	if err := k8svalidation.ValidateDeploymentFiles(deployType, files); err != nil {
+		return fmt.Errorf("generated %s files are invalid, no files were written (use --skip-validation to write them anyway):\n%w", deployType, err)
+	}
+	return nil
+}
+
+// checkPolicy checks the rendered files against the built-in policy rules and the rules in the policy directory
+func (cc *createCmd) checkPolicy(fileMap map[string][]byte) error {
+	failOn, err := policy.ParseSeverity(cc.policyFailOn)
+	if err != nil {
+		return fmt.Errorf("invalid --policy-fail-on: %w", err)
+	}
+
+	log.Info("--> Checking generated files against policy rules...")
+	rules, err := policy.LoadPolicy(cc.policyDir, true)
+	if err != nil {
+		return err
+	}
+	files, err := cc.relativeFileMap(fileMap)
+	if err != nil {
+		return err
+	}
+	input, err := policy.NewInput(files)
+	if err != nil {
+		return err
+	}
+	violations, err := policy.Evaluate(rules, input)
+	if err != nil {
+		return err
+	}
+	violations = append(violations, lintDockerfiles(input)...)
+
+	for _, violation := range violations {
+		if violation.Severity.AtLeast(failOn) {
+			log.Error(violation.String())
+		} else {
+			log.Warn(violation.String())
+		}
+	}
+	if policy.AnyAtLeast(violations, failOn) {
+		return fmt.Errorf("policy violations found with severity %s or higher, no files were written", failOn)
+	}
+	return nil
+}
+
+// relativeFileMap keys the rendered files by their slash separated path relative to the destination
+func (cc *createCmd) relativeFileMap(fileMap map[string][]byte) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(fileMap))
+	for filePath, content := range fileMap {
+		relPath, err := filepath.Rel(cc.dest, filePath)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(relPath)] = content
+	}
+	return files, nil
 }
 
 func (cc *createCmd) createFiles(detectedLang *config.DraftConfig, lowerLang string) error {
@@ -399,6 +676,10 @@ func (cc *createCmd) createFiles(detectedLang *config.DraftConfig, lowerLang str
 	if err != nil {
 		return err
 	}
+	if !hasDeploymentFiles {
+		_, err = backends.Detect(cc.dest)
+		hasDeploymentFiles = err == nil
+	}
 
 	// prompts user for dockerfile re-creation
 	if hasDockerFile && !cc.deploymentOnly {
@@ -470,20 +751,10 @@ func validateConfigInputsToPrompts(required []config.BuilderVar, provided []User
 		customInputs[variable.Name] = variable.Value
 	}
 
-	// fill in missing vars using variable default references
-	for _, variableDefault := range defaults {
-		if customInputs[variableDefault.Name] == "" && variableDefault.ReferenceVar != "" {
-			log.Debugf("variable %s is empty, using default referenceVar value from %s", variableDefault.Name, variableDefault.ReferenceVar)
-			customInputs[variableDefault.Name] = customInputs[variableDefault.ReferenceVar]
-		}
-	}
-
-	// fill in missing vars using variable default values
-	for _, variableDefault := range defaults {
-		if customInputs[variableDefault.Name] == "" && variableDefault.Value != "" {
-			log.Debugf("setting default value for %s to %s", variableDefault.Name, variableDefault.Value)
-			customInputs[variableDefault.Name] = variableDefault.Value
-		}
+	// fill in missing vars using variable defaults, evaluated in dependency order
+	customInputs, err := config.ResolveVariableDefaults(defaults, customInputs)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, variable := range required {
@@ -11,8 +11,17 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/filematches"
 	"github.com/Azure/draft/pkg/languages"
 	"github.com/Azure/draft/pkg/linguist"
 	"github.com/Azure/draft/pkg/reporeader"
@@ -130,6 +139,198 @@ func TestRunCreateDockerfileWithRepoReader(t *testing.T) {
 	}
 }
 
+func TestIntegrationConfigsCreateDockerfile(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	configPaths, err := filepath.Glob("./../test/integration/*/helm.yaml")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, configPaths)
+
+	for _, configPath := range configPaths {
+		t.Run(filepath.Base(filepath.Dir(configPath)), func(t *testing.T) {
+			templateWriter := &writers.FileMapWriter{}
+			mockCC := &createCmd{dest: ".", createConfigPath: configPath, repoReader: reporeader.FakeRepoReader{}, templateWriter: templateWriter}
+			assert.Nil(t, mockCC.initConfig())
+
+			detectedLang, lowerLang, err := mockCC.mockDetectLanguage()
+			assert.Nil(t, err)
+			assert.NotNil(t, detectedLang)
+
+			err = mockCC.generateDockerfile(detectedLang, lowerLang)
+			assert.Nil(t, err)
+			assert.NotEmpty(t, templateWriter.FileMap["Dockerfile"])
+			assert.NotEmpty(t, templateWriter.FileMap[".dockerignore"])
+		})
+	}
+}
+
+func TestCreateDeploymentProbesResourcesSecurityContext(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	deploymentFiles := map[string]string{
+		"helm":      "charts/testapp/values.yaml",
+		"kustomize": "base/deployment.yaml",
+		"manifests": "manifests/deployment.yaml",
+	}
+	for deployType, deploymentFile := range deploymentFiles {
+		t.Run(deployType, func(t *testing.T) {
+			templateWriter := &writers.FileMapWriter{}
+			testCreateConfig := CreateConfig{DeployType: deployType, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+			mockCC := createCmd{dest: ".", createConfig: &testCreateConfig, templateWriter: templateWriter}
+			assert.Nil(t, mockCC.createDeployment())
+
+			deployment := string(templateWriter.FileMap[deploymentFile])
+			assert.Contains(t, deployment, "port: http")
+			assert.Contains(t, deployment, "runAsNonRoot: true")
+			assert.Contains(t, deployment, "runAsUser: 65532")
+			assert.Contains(t, deployment, "allowPrivilegeEscalation: false")
+			assert.Contains(t, deployment, "type: RuntimeDefault")
+			for _, resource := range []string{"100m", "500m", "128Mi", "512Mi"} {
+				assert.Contains(t, deployment, resource)
+			}
+
+			// the deployment runs as the user of the Dockerfile generated in the same run
+			mockCC = createCmd{dest: ".", createConfig: &testCreateConfig, templateWriter: templateWriter, dockerfile: []byte("FROM php:8.3-apache\nUSER 33:33\n")}
+			assert.Nil(t, mockCC.createDeployment())
+			deployment = string(templateWriter.FileMap[deploymentFile])
+			assert.Contains(t, deployment, "runAsNonRoot: true")
+			assert.Contains(t, deployment, "runAsUser: 33")
+
+			// images that run as root aren't required to run as non-root
+			mockCC = createCmd{dest: ".", createConfig: &testCreateConfig, templateWriter: templateWriter, dockerfile: []byte("FROM ubuntu\nUSER root\n")}
+			assert.Nil(t, mockCC.createDeployment())
+			deployment = string(templateWriter.FileMap[deploymentFile])
+			assert.Contains(t, deployment, "runAsNonRoot: false")
+			assert.Contains(t, deployment, "runAsUser: 0")
+		})
+	}
+}
+
+func TestCreateDeploymentFromEnvFile(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			// the env file is read from the destination rather than the working directory
+			dest := t.TempDir()
+			testRepoReader := reporeader.FakeRepoReader{Files: map[string][]byte{
+				".env":                      []byte("FROM_WORKING_DIRECTORY=true\n"),
+				filepath.Join(dest, ".env"): []byte("LOG_LEVEL=info\nDATABASE_PASSWORD=hunter2\n"),
+			}}
+			testCreateConfig := CreateConfig{DeployType: deployType, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+			mockCC := createCmd{dest: dest, createConfig: &testCreateConfig, repoReader: testRepoReader, templateWriter: &writers.LocalFSWriter{}}
+			assert.Nil(t, mockCC.createDeployment())
+
+			var rendered string
+			switch deployType {
+			case "helm":
+				chart, err := loader.Load(path.Join(dest, "charts", "testapp"))
+				assert.Nil(t, err)
+				values, err := chartutil.ToRenderValues(chart, chart.Values, chartutil.ReleaseOptions{Name: "test-release"}, nil)
+				assert.Nil(t, err)
+				files, err := engine.Render(chart, values)
+				assert.Nil(t, err)
+				for _, file := range files {
+					rendered += file + "\n---\n"
+				}
+			case "kustomize":
+				kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+				resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), path.Join(dest, "base"))
+				assert.Nil(t, err)
+				out, err := resMap.AsYaml()
+				assert.Nil(t, err)
+				rendered = string(out)
+			case "manifests":
+				for _, file := range []string{"deployment.yaml", "configmap.yaml", "secret.yaml"} {
+					content, err := os.ReadFile(path.Join(dest, "manifests", file))
+					assert.Nil(t, err)
+					rendered += string(content) + "\n---\n"
+				}
+			}
+
+			assert.Contains(t, rendered, "kind: ConfigMap")
+			assert.Contains(t, rendered, "LOG_LEVEL")
+			assert.Contains(t, rendered, "kind: Secret")
+			assert.Contains(t, rendered, "DATABASE_PASSWORD")
+			assert.NotContains(t, rendered, "hunter2")
+			assert.NotContains(t, rendered, "FROM_WORKING_DIRECTORY")
+			assert.Contains(t, rendered, "envFrom:")
+			assert.Contains(t, rendered, "configMapRef:")
+			assert.Contains(t, rendered, "secretRef:")
+		})
+	}
+}
+
+func TestCreateDeploymentValidation(t *testing.T) {
+	flagVariablesMap = map[string]string{"SERVICEPORT": "http-port"}
+	defer func() { flagVariablesMap = map[string]string{} }()
+
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			templateWriter := &writers.FileMapWriter{}
+			testCreateConfig := CreateConfig{DeployType: deployType, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+			mockCC := createCmd{dest: ".", createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: templateWriter}
+			err := mockCC.createDeployment()
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "no files were written")
+			assert.Empty(t, templateWriter.FileMap)
+
+			mockCC.skipValidation = true
+			assert.Nil(t, mockCC.createDeployment())
+			assert.NotEmpty(t, templateWriter.FileMap)
+		})
+	}
+}
+
+func TestCreateDeploymentEnvironments(t *testing.T) {
+	// variables set by draft while rendering the environments don't change which files are generated
+	flagVariablesMap = map[string]string{"staging.NAMESPACE": "staging-ns", "prod.IMAGETAG": "v1.0.0", "dev.REPLICAS": "2", "ENVIRONMENT": "qa", "DRAFT_ENVIRONMENT_FILES": "true"}
+	dest := t.TempDir()
+	testCreateConfig := CreateConfig{DeployType: "kustomize", DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}, {Name: "NAMESPACE", Value: "testapp-ns"}}}
+	mockCC := createCmd{dest: dest, createConfig: &testCreateConfig, environments: []string{"dev", "staging", "prod"}, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}}
+	assert.Nil(t, mockCC.createDeployment())
+
+	overlays, err := filematches.FindKustomizeOverlays(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "prod", "staging"}, overlays)
+	kustomization, err := os.ReadFile(path.Join(dest, "overlays", "prod", "kustomization.yaml"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(kustomization), "patchesStrategicMerge", "the deprecated field is replaced by patches")
+
+	tests := []struct {
+		environment string
+		replicas    string
+		namespace   string
+		image       string
+	}{
+		{environment: "dev", replicas: "2", namespace: "testapp-ns", image: "testapp:0.1.0"},
+		{environment: "staging", replicas: "2", namespace: "staging-ns", image: "testapp:0.1.0"},
+		{environment: "prod", replicas: "1", namespace: "testapp-ns", image: "testapp:v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+			resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), path.Join(dest, "overlays", tt.environment))
+			assert.Nil(t, err)
+
+			deployments := resMap.GetMatchingResourcesByAnyId(func(id resid.ResId) bool { return id.Kind == "Deployment" })
+			if !assert.Len(t, deployments, 1) {
+				return
+			}
+			deployment := deployments[0]
+			assert.Equal(t, tt.environment+"-testapp", deployment.GetName())
+			assert.Equal(t, tt.namespace, deployment.GetNamespace())
+			replicas, err := deployment.Pipe(kyaml.Lookup("spec", "replicas"))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.replicas, replicas.YNode().Value)
+			image, err := deployment.Pipe(kyaml.Lookup("spec", "template", "spec", "containers", "[name=testapp]", "image"))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.image, image.YNode().Value)
+		})
+	}
+
+	mockCC = createCmd{dest: t.TempDir(), createConfig: &testCreateConfig, environments: []string{"Staging"}, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}}
+	assert.NotNil(t, mockCC.createDeployment())
+}
+
 func TestInitConfig(t *testing.T) {
 	mockCC := &createCmd{}
 	mockCC.createConfig = &CreateConfig{}
@@ -172,6 +373,38 @@ func TestValidateConfigInputsToPromptsMissing(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestValidateConfigInputsToPromptsDependencyOrder(t *testing.T) {
+	required := []config.BuilderVar{
+		{Name: "APPNAME"},
+		{Name: "SERVICEPORT"},
+		{Name: "SERVICENAME"},
+		{Name: "PORT"},
+	}
+	provided := []UserInputs{
+		{Name: "APPNAME", Value: "MyApp"},
+	}
+	defaults := []config.BuilderVarDefault{
+		{Name: "SERVICEPORT", Value: "80", ReferenceVar: "PORT"},
+		{Name: "SERVICENAME", Value: "{{lower APPNAME}}-svc"},
+		{Name: "PORT", Value: "8080"},
+	}
+
+	vars, err := validateConfigInputsToPrompts(required, provided, defaults)
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", vars["SERVICEPORT"])
+	assert.Equal(t, "myapp-svc", vars["SERVICENAME"])
+}
+
+func TestValidateConfigInputsToPromptsCycle(t *testing.T) {
+	defaults := []config.BuilderVarDefault{
+		{Name: "A", Value: "{{B}}"},
+		{Name: "B", Value: "{{A}}"},
+	}
+
+	_, err := validateConfigInputsToPrompts([]config.BuilderVar{{Name: "A"}}, []UserInputs{}, defaults)
+	assert.NotNil(t, err)
+}
+
 func (mcc *createCmd) mockDetectLanguage() (*config.DraftConfig, string, error) {
 	hasGo := false
 	hasGoMod := false
@@ -237,13 +470,38 @@ func TestDefaultValues(t *testing.T) {
 	assert.Equal(t, currentDirDefaultFlagValue, ".")
 }
 
+// getAllDeploymentFiles returns the files generated from the deployment templates in src using the
+// default variables, skipping templates excluded by file conditions or moved by a destination override
 func getAllDeploymentFiles(src string) (error, []string) {
 	deploymentFiles := []string{}
-	err := filepath.Walk(src,
+	deployConfig, err := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "").GetConfig(filepath.Base(src))
+	if err != nil {
+		return err, nil
+	}
+	defaultInputs := make(map[string]string)
+	for _, variableDefault := range deployConfig.VariableDefaults {
+		defaultInputs[variableDefault.Name] = variableDefault.Value
+	}
+
+	err = filepath.Walk(src,
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
+			relPath, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			skip := !deployConfig.IsFileIncluded(relPath, defaultInputs)
+			if override, ok := deployConfig.GetNameOverride(relPath); ok && override.Destination != "" {
+				skip = true
+			}
+			if skip && info.IsDir() {
+				return filepath.SkipDir
+			}
+			if skip {
+				return nil
+			}
 			filePath := strings.ReplaceAll(path, src, "./..")
 			if info.Name() != "draft.yaml" {
 				deploymentFiles = append(deploymentFiles, filePath)
@@ -252,3 +510,33 @@ func getAllDeploymentFiles(src string) (error, []string) {
 		})
 	return err, deploymentFiles
 }
+
+func TestCreateSharedVariables(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	templateWriter := &writers.FileMapWriter{}
+	testCreateConfig := CreateConfig{
+		LanguageVariables: []UserInputs{{Name: "PORT", Value: "3000"}, {Name: "VERSION", Value: "1.22"}},
+		DeployType:        "manifests",
+		DeployVariables:   []UserInputs{{Name: "SERVICEPORT", Value: "80"}},
+	}
+	mockCC := createCmd{dest: ".", appName: "shared-app", lang: "go", createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: templateWriter}
+	mockCC.initVariables()
+
+	detectedLang, lowerLang, err := mockCC.mockDetectLanguage()
+	assert.Nil(t, err)
+	assert.Nil(t, mockCC.generateDockerfile(detectedLang, lowerLang))
+	assert.Nil(t, mockCC.createDeployment())
+
+	assert.Contains(t, string(templateWriter.FileMap["Dockerfile"]), "EXPOSE 3000")
+	deployment := string(templateWriter.FileMap["manifests/deployment.yaml"])
+	assert.Contains(t, deployment, "containerPort: 3000")
+	assert.Contains(t, deployment, "name: shared-app")
+	assert.Equal(t, "3000", mockCC.variables["PORT"])
+
+	// values set for a phase in the config file take precedence over the shared ones
+	testCreateConfig.DeployVariables = append(testCreateConfig.DeployVariables, UserInputs{Name: "PORT", Value: "9000"}, UserInputs{Name: "APPNAME", Value: "config-app"})
+	assert.Nil(t, mockCC.createDeployment())
+	deployment = string(templateWriter.FileMap["manifests/deployment.yaml"])
+	assert.Contains(t, deployment, "containerPort: 9000")
+	assert.Contains(t, deployment, "name: config-app")
+}
@@ -1,13 +1,35 @@
 package cmd
 
+import (
+	"errors"
+	"fmt"
+)
+
 type CreateConfig struct {
 	DeployType        string       `yaml:"deployType"`
 	LanguageType      string       `yaml:"languageType"`
 	DeployVariables   []UserInputs `yaml:"deployVariables"`
 	LanguageVariables []UserInputs `yaml:"languageVariables"`
+	Environments      []string     `yaml:"environments"`
 }
 
 type UserInputs struct {
 	Name  string `yaml:"name"`
 	Value string `yaml:"value"`
 }
+
+// Validate checks the config for mistakes that decoding doesn't catch, such as unnamed variables
+func (c *CreateConfig) Validate() error {
+	var errs []error
+	for i, input := range c.DeployVariables {
+		if input.Name == "" {
+			errs = append(errs, fmt.Errorf("deployVariables[%d] has no name", i))
+		}
+	}
+	for i, input := range c.LanguageVariables {
+		if input.Name == "" {
+			errs = append(errs, fmt.Errorf("languageVariables[%d] has no name", i))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/doctor"
+	"github.com/Azure/draft/pkg/policy"
+)
+
+type doctorCmd struct {
+	dest   string
+	format string
+
+	out io.Writer
+}
+
+func newDoctorCmd() *cobra.Command {
+	dc := &doctorCmd{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "doctor [flags]",
+		Short: "Checks that the Dockerfile and deployment files of a project agree with each other",
+		Long: `This command reports inconsistencies between the files of a project, such as a Dockerfile that exposes a different port than the deployment's containerPort, the helm chart's containerPort value or the service's targetPort.
+The Dockerfile's PORT environment variable, or else its first EXPOSE, is taken as the port the application listens on.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return dc.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&dc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringVarP(&dc.format, "format", "o", textFormat, "specify the output format (text, json)")
+
+	return cmd
+}
+
+func (dc *doctorCmd) run() error {
+	if _, err := parseLintFlags(dc.format, string(policy.SeverityError)); err != nil {
+		return err
+	}
+
+	files, err := readProjectFiles(dc.dest)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Dockerfile or deployment files found in %s", dc.dest)
+	}
+
+	violations, err := doctor.Check(files)
+	if err != nil {
+		return err
+	}
+	if err = printViolations(dc.out, dc.format, violations); err != nil {
+		return err
+	}
+	if policy.AnyAtLeast(violations, policy.SeverityError) {
+		return fmt.Errorf("found %d inconsistencies between the project files", len(violations))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(newDoctorCmd())
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/doctor"
+	"github.com/Azure/draft/pkg/reporeader/readers"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestCreateDeploymentDockerfileDefaults(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Dockerfile"), []byte("FROM node:20\nENV PORT 3000\nEXPOSE 3000\n"), 0644))
+
+	testCreateConfig := CreateConfig{DeployType: "manifests", DeployVariables: []UserInputs{{Name: "APPNAME", Value: "testapp"}, {Name: "SERVICEPORT", Value: "80"}}}
+	mockCC := createCmd{dest: dest, createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}, repoReader: &readers.LocalFSReader{}}
+	assert.Nil(t, mockCC.createDeployment())
+
+	deployment, err := os.ReadFile(filepath.Join(dest, "manifests", "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(deployment), "containerPort: 3000")
+
+	var out bytes.Buffer
+	dc := doctorCmd{dest: dest, format: textFormat, out: &out}
+	assert.Nil(t, dc.run())
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Dockerfile"), []byte("FROM node:20\nENV PORT 8080\nEXPOSE 8080\n"), 0644))
+	out.Reset()
+	assert.NotNil(t, dc.run())
+	assert.Contains(t, out.String(), doctor.CheckContainerPort)
+	assert.Contains(t, out.String(), "don't include the Dockerfile port 8080")
+}
+
+func TestCreateDeploymentGeneratedDockerfilePort(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	templateWriter := &writers.FileMapWriter{}
+	testCreateConfig := CreateConfig{DeployType: "manifests", DeployVariables: []UserInputs{{Name: "APPNAME", Value: "testapp"}, {Name: "SERVICEPORT", Value: "80"}}}
+	mockCC := createCmd{dest: ".", createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: templateWriter, variables: map[string]string{"PORT": "5000"}}
+	assert.Nil(t, mockCC.createDeployment())
+	assert.Contains(t, string(templateWriter.FileMap["manifests/deployment.yaml"]), "containerPort: 5000")
+
+	explicitConfig := CreateConfig{DeployType: "manifests", DeployVariables: []UserInputs{{Name: "APPNAME", Value: "testapp"}, {Name: "SERVICEPORT", Value: "80"}, {Name: "PORT", Value: "9000"}}}
+	mockCC.createConfig = &explicitConfig
+	assert.Nil(t, mockCC.createDeployment())
+	assert.Contains(t, string(templateWriter.FileMap["manifests/deployment.yaml"]), "containerPort: 9000")
+}
+
+func TestDoctorInvalidFormat(t *testing.T) {
+	assert.NotNil(t, (&doctorCmd{dest: t.TempDir(), format: "xml"}).run())
+}
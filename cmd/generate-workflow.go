@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"github.com/manifoldco/promptui"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
 
+	"github.com/Azure/draft/pkg/backends"
+	"github.com/Azure/draft/pkg/prompts"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/variables"
 	"github.com/Azure/draft/pkg/workflows"
 )
 
@@ -14,6 +19,7 @@ type generateWorkflowCmd struct {
 	dest           string
 	deployType     string
 	flagVariables  []string
+	variableFile   string
 	templateWriter templatewriter.TemplateWriter
 }
 
@@ -29,12 +35,20 @@ func newGenerateWorkflowCmd() *cobra.Command {
 		Long: `This command will generate a Github workflow to build and deploy an application containerized 
 with draft on AKS. This command assumes the 'setup-gh' command has been run properly.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			flagValuesMap = make(map[string]string)
-			if cmd.Flags().NFlag() != 0 {
-				flagValuesMap = gwCmd.workflowConfig.SetFlagValuesToMap()
+			vars, err := variables.Load(gwCmd.flagVariables, gwCmd.variableFile)
+			if err != nil {
+				return err
+			}
+			if err = gwCmd.selectDeployType(); err != nil {
+				return err
 			}
+			if err = gwCmd.selectTarget(vars); err != nil {
+				return err
+			}
+			flagValuesMap = gwCmd.workflowConfig.SetFlagValuesToMap()
+			maps.Copy(flagValuesMap, vars)
 			log.Info("--> Generating Github workflow")
-			if err := workflows.CreateWorkflows(gwCmd.dest, gwCmd.deployType, gwCmd.flagVariables, gwCmd.templateWriter, flagValuesMap); err != nil {
+			if err := workflows.CreateWorkflows(gwCmd.dest, gwCmd.deployType, gwCmd.templateWriter, flagValuesMap); err != nil {
 				return err
 			}
 
@@ -52,12 +66,49 @@ with draft on AKS. This command assumes the 'setup-gh' command has been run prop
 	f.StringVarP(&gwCmd.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
 	f.StringVarP(&gwCmd.workflowConfig.BranchName, "branch", "b", emptyDefaultFlagValue, "specify the Github branch to automatically deploy from")
 	f.StringVar(&gwCmd.deployType, "deploy-type", emptyDefaultFlagValue, "specify the type of deployment")
-	f.StringArrayVarP(&gwCmd.flagVariables, "variable", "", []string{}, "pass additional variables")
+	f.StringArrayVarP(&gwCmd.flagVariables, "variable", "", []string{}, "pass additional variables, read a value from a file with --variable NAME=@path")
+	f.StringVar(&gwCmd.variableFile, "variable-file", emptyDefaultFlagValue, "specify a yaml or .env file of variables, overridden by DRAFT_VAR_ environment variables and --variable flags")
 	f.StringVarP(&gwCmd.workflowConfig.BuildContextPath, "build-context-path", "x", emptyDefaultFlagValue, "specify the docker build context path")
+	f.StringVar(&gwCmd.workflowConfig.KustomizeOverlay, "overlay", emptyDefaultFlagValue, "specify the kustomize overlay to deploy (eg. staging)")
+	f.StringVar(&gwCmd.workflowConfig.HelmChart, "chart", emptyDefaultFlagValue, "specify the path to the helm chart to deploy (eg. charts/myapp)")
 	gwCmd.templateWriter = &writers.LocalFSWriter{}
 	return cmd
 }
 
+// selectDeployType prompts for the deploy type of the workflow unless it's set
+func (gwCmd *generateWorkflowCmd) selectDeployType() error {
+	if gwCmd.deployType != "" {
+		return nil
+	}
+	selection := &promptui.Select{
+		Label: "Select k8s Deployment Type",
+		Items: backends.DeployTypes(),
+	}
+	var err error
+	_, gwCmd.deployType, err = selection.Run()
+	return err
+}
+
+// selectTarget picks the helm chart or kustomize overlay the workflow deploys, unless the variables already set it,
+// prompting for one when the project has several
+func (gwCmd *generateWorkflowCmd) selectTarget(vars map[string]string) error {
+	backend, err := backends.Get(gwCmd.deployType)
+	if err != nil {
+		return err
+	}
+	switch backend.(type) {
+	case *backends.Helm:
+		if vars[workflows.ChartPathVariable] == "" {
+			gwCmd.workflowConfig.HelmChart, err = prompts.SelectHelmChart(gwCmd.dest, gwCmd.workflowConfig.HelmChart)
+		}
+	case *backends.Kustomize:
+		if vars[workflows.KustomizePathVariable] == "" {
+			gwCmd.workflowConfig.KustomizeOverlay, err = prompts.SelectKustomizeOverlay(gwCmd.dest, gwCmd.workflowConfig.KustomizeOverlay)
+		}
+	}
+	return err
+}
+
 func init() {
 	rootCmd.AddCommand(newGenerateWorkflowCmd())
 }
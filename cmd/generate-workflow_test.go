@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/workflows"
+)
+
+func TestGenerateWorkflowSelectTarget(t *testing.T) {
+	dest := t.TempDir()
+	chart, err := os.ReadFile("../test/templates/helm/charts/Chart.yaml")
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "charts", "web"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "charts", "web", "Chart.yaml"), chart, 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "overlays", "production"), 0755))
+
+	gwCmd := &generateWorkflowCmd{dest: dest, deployType: "helm"}
+	assert.Nil(t, gwCmd.selectTarget(map[string]string{}))
+	assert.Equal(t, "charts/web", gwCmd.workflowConfig.HelmChart)
+
+	gwCmd = &generateWorkflowCmd{dest: dest, deployType: "helm"}
+	assert.Nil(t, gwCmd.selectTarget(map[string]string{workflows.ChartPathVariable: "./charts/api"}))
+	assert.Empty(t, gwCmd.workflowConfig.HelmChart)
+
+	gwCmd = &generateWorkflowCmd{dest: dest, deployType: "helm", workflowConfig: workflows.WorkflowConfig{HelmChart: "charts/api"}}
+	assert.NotNil(t, gwCmd.selectTarget(map[string]string{}))
+
+	gwCmd = &generateWorkflowCmd{dest: dest, deployType: "kustomize"}
+	assert.Nil(t, gwCmd.selectTarget(map[string]string{}))
+	assert.Equal(t, "production", gwCmd.workflowConfig.KustomizeOverlay)
+
+	gwCmd = &generateWorkflowCmd{dest: dest, deployType: "manifests"}
+	assert.Nil(t, gwCmd.selectTarget(map[string]string{}))
+	assert.Equal(t, workflows.WorkflowConfig{}, gwCmd.workflowConfig)
+
+	assert.NotNil(t, (&generateWorkflowCmd{dest: dest, deployType: "invalid"}).selectTarget(map[string]string{}))
+}
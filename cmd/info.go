@@ -3,44 +3,74 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
+	"github.com/Azure/draft/pkg/addons"
+	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/deployments"
 	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/workflows"
 	"github.com/Azure/draft/template"
 )
 
 type Format string
 
 const (
-	JSON Format = "json"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
 )
 
 type infoCmd struct {
 	format string
 	info   *draftInfo
+	out    io.Writer
 }
 
-// draftConfigInfo is a struct that contains information about the example usage of variables for a single draft.yaml
+// draftConfigInfo is a struct that contains information about the variables of a single draft.yaml
 type draftConfigInfo struct {
-	Name                  string              `json:"name"`
-	DisplayName           string              `json:"displayName,omitempty"`
-	VariableExampleValues map[string][]string `json:"variableExampleValues,omitempty"`
+	Name string `json:"name" yaml:"name"`
+	// Provider is the provider of an addon
+	Provider              string              `json:"provider,omitempty" yaml:"provider,omitempty"`
+	DisplayName           string              `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	VariableExampleValues map[string][]string `json:"variableExampleValues,omitempty" yaml:"variableExampleValues,omitempty"`
+	Variables             []variableInfo      `json:"variables" yaml:"variables"`
+}
+
+// variableInfo describes a variable of a draft.yaml, combining its declaration with its default
+type variableInfo struct {
+	Name          string   `json:"name" yaml:"name"`
+	Description   string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Type          string   `json:"type,omitempty" yaml:"type,omitempty"`
+	ExampleValues []string `json:"exampleValues,omitempty" yaml:"exampleValues,omitempty"`
+	Default       string   `json:"default,omitempty" yaml:"default,omitempty"`
+	ReferenceVar  string   `json:"referenceVar,omitempty" yaml:"referenceVar,omitempty"`
+	DisablePrompt bool     `json:"disablePrompt,omitempty" yaml:"disablePrompt,omitempty"`
 }
 
 type draftInfo struct {
-	SupportedLanguages       []draftConfigInfo `json:"supportedLanguages"`
-	SupportedDeploymentTypes []string          `json:"supportedDeploymentTypes"`
+	SupportedLanguages       []draftConfigInfo `json:"supportedLanguages" yaml:"supportedLanguages"`
+	SupportedDeploymentTypes []string          `json:"supportedDeploymentTypes" yaml:"supportedDeploymentTypes"`
+	DeploymentTypes          []draftConfigInfo `json:"deploymentTypes" yaml:"deploymentTypes"`
+	Workflows                []draftConfigInfo `json:"workflows" yaml:"workflows"`
+	Addons                   []draftConfigInfo `json:"addons" yaml:"addons"`
 }
 
 func newInfoCmd() *cobra.Command {
-	ic := &infoCmd{}
+	ic := &infoCmd{out: os.Stdout}
 	var cmd = &cobra.Command{
 		Use:   "info",
 		Short: "Prints draft supported values in machine-readable format",
-		Long:  `This command prints information about the current draft environment and supported values such as supported dockerfile languages and deployment manifest types.`,
+		Long: `This command prints information about the current draft environment and supported values such as supported dockerfile languages, deployment manifest types, workflows and addons.
+Every template is listed with its variables, including their descriptions, types, example values and defaults.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := ic.run(); err != nil {
 				return err
@@ -49,38 +79,193 @@ func newInfoCmd() *cobra.Command {
 		},
 	}
 	f := cmd.Flags()
-	f.StringVarP(&ic.format, "format", "f", ".", "specify the format to print draft information in (json, yaml, etc)")
+	f.StringVarP(&ic.format, "format", "f", string(JSON), "specify the format to print draft information in (json, yaml, table)")
 
 	return cmd
 }
 
 func (ic *infoCmd) run() error {
+	switch Format(ic.format) {
+	case JSON, YAML, Table:
+	default:
+		return fmt.Errorf("invalid format %q, must be one of json, yaml or table", ic.format)
+	}
+
+	info, err := getDraftInfo()
+	if err != nil {
+		return err
+	}
+	ic.info = info
+
+	switch Format(ic.format) {
+	case YAML:
+		encoder := yaml.NewEncoder(ic.out)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(ic.info); err != nil {
+			return fmt.Errorf("could not marshal draft info into yaml: %w", err)
+		}
+		return encoder.Close()
+	case Table:
+		return printInfoTable(ic.out, ic.info)
+	}
+
+	infoText, err := json.MarshalIndent(ic.info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal draft info into json: %w", err)
+	}
+	_, err = fmt.Fprintln(ic.out, string(infoText))
+	return err
+}
+
+// getDraftInfo collects the languages, deployment types, workflows and addons draft supports, sorted by name
+func getDraftInfo() (*draftInfo, error) {
 	log.Debugf("getting supported languages")
 	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
 	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "")
+	w := workflows.CreateWorkflowsFromEmbedFS(template.Workflows, "")
 
+	languageNames := l.Names()
+	sort.Strings(languageNames)
 	languagesInfo := make([]draftConfigInfo, 0)
-	for _, lang := range l.Names() {
+	for _, lang := range languageNames {
 		langConfig := l.GetConfig(lang)
-		newConfig := draftConfigInfo{
-			Name:                  lang,
-			DisplayName:           langConfig.DisplayName,
-			VariableExampleValues: langConfig.GetVariableExampleValues(),
-		}
+		newConfig := newDraftConfigInfo(lang, langConfig)
+		newConfig.VariableExampleValues = langConfig.GetVariableExampleValues()
 		languagesInfo = append(languagesInfo, newConfig)
 	}
 
-	ic.info = &draftInfo{
-		SupportedLanguages:       languagesInfo,
-		SupportedDeploymentTypes: d.DeployTypes(),
+	deployTypes := d.DeployTypes()
+	sort.Strings(deployTypes)
+	deploymentsInfo := make([]draftConfigInfo, 0)
+	for _, deployType := range deployTypes {
+		deployConfig, err := d.GetConfig(deployType)
+		if err != nil {
+			return nil, err
+		}
+		deploymentsInfo = append(deploymentsInfo, newDraftConfigInfo(deployType, deployConfig))
 	}
 
-	infoText, err := json.MarshalIndent(ic.info, "", "  ")
+	workflowTypes := w.DeployTypes()
+	sort.Strings(workflowTypes)
+	workflowsInfo := make([]draftConfigInfo, 0)
+	for _, deployType := range workflowTypes {
+		workflowConfig, err := w.GetConfig(deployType)
+		if err != nil {
+			return nil, err
+		}
+		workflowsInfo = append(workflowsInfo, newDraftConfigInfo(deployType, workflowConfig))
+	}
+
+	providers, err := addons.Providers(template.Addons)
 	if err != nil {
-		return fmt.Errorf("could not marshal draft info into json: %w", err)
+		return nil, err
+	}
+	sort.Strings(providers)
+	addonsInfo := make([]draftConfigInfo, 0)
+	for _, provider := range providers {
+		addonNames, err := addons.Names(template.Addons, provider)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(addonNames)
+		for _, addon := range addonNames {
+			addonConfig, err := addons.GetAddonConfig(template.Addons, provider, addon)
+			if err != nil {
+				return nil, err
+			}
+			newConfig := newDraftConfigInfo(addon, &addonConfig.DraftConfig)
+			newConfig.Provider = provider
+			addonsInfo = append(addonsInfo, newConfig)
+		}
+	}
+
+	return &draftInfo{
+		SupportedLanguages:       languagesInfo,
+		SupportedDeploymentTypes: deployTypes,
+		DeploymentTypes:          deploymentsInfo,
+		Workflows:                workflowsInfo,
+		Addons:                   addonsInfo,
+	}, nil
+}
+
+// newDraftConfigInfo describes the variables of draftConfig, in the order they're declared followed by the
+// variables that only have a default, which are never prompted for
+func newDraftConfigInfo(name string, draftConfig *config.DraftConfig) draftConfigInfo {
+	defaults := make(map[string]config.BuilderVarDefault)
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		defaults[variableDefault.Name] = variableDefault
+	}
+
+	variables := make([]variableInfo, 0)
+	declared := make(map[string]bool)
+	for _, variable := range draftConfig.Variables {
+		declared[variable.Name] = true
+		variables = append(variables, variableInfo{
+			Name:          variable.Name,
+			Description:   variable.Description,
+			Type:          variable.VarType,
+			ExampleValues: variable.ExampleValues,
+			Default:       defaults[variable.Name].Value,
+			ReferenceVar:  defaults[variable.Name].ReferenceVar,
+			DisablePrompt: variable.IsPromptDisabled,
+		})
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if declared[variableDefault.Name] {
+			continue
+		}
+		variables = append(variables, variableInfo{
+			Name:          variableDefault.Name,
+			Default:       variableDefault.Value,
+			ReferenceVar:  variableDefault.ReferenceVar,
+			DisablePrompt: true,
+		})
+	}
+
+	return draftConfigInfo{
+		Name:        name,
+		DisplayName: draftConfig.DisplayName,
+		Variables:   variables,
+	}
+}
+
+// printInfoTable prints a row for every variable of every template
+func printInfoTable(out io.Writer, info *draftInfo) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tVARIABLE\tTYPE\tDEFAULT\tPROMPT\tDESCRIPTION")
+
+	kinds := []struct {
+		kind    string
+		configs []draftConfigInfo
+	}{
+		{"language", info.SupportedLanguages},
+		{"deployment", info.DeploymentTypes},
+		{"workflow", info.Workflows},
+		{"addon", info.Addons},
+	}
+	for _, k := range kinds {
+		for _, configInfo := range k.configs {
+			name := configInfo.Name
+			if configInfo.Provider != "" {
+				name = configInfo.Provider + "/" + name
+			}
+			if len(configInfo.Variables) == 0 {
+				fmt.Fprintf(tw, "%s\t%s\t-\t\t\t\t\n", k.kind, name)
+			}
+			for _, variable := range configInfo.Variables {
+				defaultValue := variable.Default
+				if variable.ReferenceVar != "" {
+					defaultValue = "same as " + variable.ReferenceVar
+				}
+				prompt := "yes"
+				if variable.DisablePrompt {
+					prompt = "no"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", k.kind, name, variable.Name, variable.Type, defaultValue, prompt, strings.TrimSpace(variable.Description))
+			}
+		}
 	}
-	fmt.Println(string(infoText))
-	return nil
+	return tw.Flush()
 }
 
 func init() {
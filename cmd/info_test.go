@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestInfoMatchesSchema(t *testing.T) {
+	schema, err := os.ReadFile("../test/info_schema.json")
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	ic := infoCmd{format: string(JSON), out: &out}
+	assert.Nil(t, ic.run())
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(out.Bytes()))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "draft info output doesn't match test/info_schema.json: %v", result.Errors())
+}
+
+func TestInfo(t *testing.T) {
+	var out bytes.Buffer
+	ic := infoCmd{format: string(JSON), out: &out}
+	assert.Nil(t, ic.run())
+	var info draftInfo
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &info))
+
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, info.SupportedDeploymentTypes)
+	assert.Len(t, info.DeploymentTypes, 3)
+	assert.Len(t, info.Workflows, 3)
+	assert.Contains(t, info.Addons, draftConfigInfo{
+		Name:     "webapp_routing",
+		Provider: "azure",
+		Variables: []variableInfo{
+			{Name: "ingress-tls-cert-keyvault-uri", Description: "the keyvault uri for the tls certificate", ExampleValues: []string{"https://my-vault.vault.azure.net/certificates/my-cert"}},
+			{Name: "ingress-use-osm-mtls", Description: "use open service mesh mutual-tls", Type: "bool"},
+			{Name: "ingress-host", Description: "specify the host of the ingress resource", ExampleValues: []string{"my-app.example.com"}},
+		},
+	})
+
+	manifests := info.DeploymentTypes[2]
+	assert.Equal(t, "manifests", manifests.Name)
+	assert.Contains(t, manifests.Variables, variableInfo{Name: "SERVICEPORT", Description: "the port the service uses to make the application accessible from outside the cluster", ReferenceVar: "PORT"})
+	assert.Contains(t, manifests.Variables, variableInfo{Name: "PORT", Description: "the port exposed in the application", Default: "80"})
+
+	workflow := info.Workflows[2]
+	assert.Equal(t, "manifests", workflow.Name)
+	assert.Contains(t, workflow.Variables, variableInfo{Name: "DEPLOYMENTMANIFESTPATH", Default: "./manifests", DisablePrompt: true})
+
+	var yamlOut bytes.Buffer
+	ic = infoCmd{format: string(YAML), out: &yamlOut}
+	assert.Nil(t, ic.run())
+	var yamlInfo draftInfo
+	assert.Nil(t, yaml.Unmarshal(yamlOut.Bytes(), &yamlInfo))
+	assert.Equal(t, info, yamlInfo)
+}
+
+func TestInfoTable(t *testing.T) {
+	var out bytes.Buffer
+	ic := infoCmd{format: string(Table), out: &out}
+	assert.Nil(t, ic.run())
+	assert.Regexp(t, `^KIND\s+NAME\s+VARIABLE\s+TYPE\s+DEFAULT\s+PROMPT\s+DESCRIPTION\n`, out.String())
+	assert.Regexp(t, `\ndeployment\s+manifests\s+SERVICEPORT\s+same as PORT\s+yes\s+the port the service uses`, out.String())
+	assert.Regexp(t, `\naddon\s+azure/webapp_routing\s+ingress-use-osm-mtls\s+bool\s+yes\s+use open service mesh mutual-tls\n`, out.String())
+
+	ic = infoCmd{format: "xml", out: &out}
+	assert.NotNil(t, ic.run())
+}
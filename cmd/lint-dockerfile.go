@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/dockerfilelint"
+	"github.com/Azure/draft/pkg/doctor"
+	"github.com/Azure/draft/pkg/policy"
+)
+
+type lintDockerfileCmd struct {
+	dest   string
+	ports  []string
+	format string
+	failOn string
+
+	out io.Writer
+}
+
+func newLintDockerfileCmd() *cobra.Command {
+	ldc := &lintDockerfileCmd{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "dockerfile [Dockerfile...]",
+		Short: "Checks Dockerfiles for common issues",
+		Long: `This command checks Dockerfiles for common issues: ADD used instead of COPY, base images without a pinned tag, running as root, a missing EXPOSE for the deployment's container port and apt-get installs without cleanup.
+It checks the Dockerfile in the project directory unless paths are given. The container ports are read from the project's deployment files unless --port is set.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ldc.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&ldc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringSliceVar(&ldc.ports, "port", []string{}, "specify the container port the Dockerfile should expose, instead of reading it from the deployment files")
+	f.StringVarP(&ldc.format, "format", "o", textFormat, "specify the output format (text, json)")
+	f.StringVar(&ldc.failOn, "fail-on", string(policy.SeverityError), "specify the lowest severity that fails the lint (info, warning, error)")
+
+	return cmd
+}
+
+func (ldc *lintDockerfileCmd) run(dockerfilePaths []string) error {
+	failOn, err := parseLintFlags(ldc.format, ldc.failOn)
+	if err != nil {
+		return err
+	}
+	if len(dockerfilePaths) == 0 {
+		dockerfilePaths = []string{filepath.Join(ldc.dest, "Dockerfile")}
+	}
+
+	ports := ldc.ports
+	if len(ports) == 0 {
+		ports = ldc.deploymentPorts()
+	}
+
+	var violations []policy.Violation
+	for _, dockerfilePath := range dockerfilePaths {
+		content, err := os.ReadFile(dockerfilePath)
+		if err != nil {
+			return fmt.Errorf("reading Dockerfile: %w", err)
+		}
+		violations = append(violations, dockerfilelint.Lint(dockerfilePath, content, dockerfilelint.Options{Ports: ports})...)
+	}
+
+	if err = printViolations(ldc.out, ldc.format, violations); err != nil {
+		return err
+	}
+	if policy.AnyAtLeast(violations, failOn) {
+		return fmt.Errorf("issues found in the Dockerfile with severity %s or higher", failOn)
+	}
+	return nil
+}
+
+// deploymentPorts returns the container ports of the deployment files in the project directory. Without
+// deployment files, or when they can't be rendered, only a missing EXPOSE is reported.
+func (ldc *lintDockerfileCmd) deploymentPorts() []string {
+	files, err := readProjectFiles(ldc.dest)
+	if err != nil {
+		log.Debugf("not checking EXPOSE against the deployment: %s", err)
+		return nil
+	}
+	input, err := policy.NewInput(files)
+	if err != nil {
+		log.Debugf("not checking EXPOSE against the deployment: %s", err)
+		return nil
+	}
+	return doctor.ContainerPorts(input.Manifests)
+}
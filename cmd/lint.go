@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/backends"
+	"github.com/Azure/draft/pkg/dockerfile"
+	"github.com/Azure/draft/pkg/dockerfilelint"
+	"github.com/Azure/draft/pkg/doctor"
+	"github.com/Azure/draft/pkg/policy"
+)
+
+const textFormat = "text"
+
+type lintCmd struct {
+	dest      string
+	policyDir string
+	format    string
+	failOn    string
+
+	out io.Writer
+}
+
+// lintResult is the json output of draft lint
+type lintResult struct {
+	Violations []policy.Violation `json:"violations"`
+	Summary    policy.Summary     `json:"summary"`
+}
+
+func newLintCmd() *cobra.Command {
+	lc := &lintCmd{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "lint [flags]",
+		Short: "Checks the Dockerfile and deployment files against policy rules",
+		Long: `This command renders the Dockerfile and the helm charts, kustomizations and manifests of a project and checks them against draft's built-in policy rules, together with any rules in the --policy directory.
+It exits with an error when a violation is at least as severe as --fail-on.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lc.run()
+		},
+	}
+	cmd.AddCommand(newLintDockerfileCmd())
+
+	f := cmd.Flags()
+	f.StringVarP(&lc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringVar(&lc.policyDir, "policy", emptyDefaultFlagValue, "specify a directory of policy rules to check in addition to the built-in rules")
+	f.StringVarP(&lc.format, "format", "o", textFormat, "specify the output format (text, json)")
+	f.StringVar(&lc.failOn, "fail-on", string(policy.SeverityError), "specify the lowest severity that fails the lint (info, warning, error)")
+
+	return cmd
+}
+
+func (lc *lintCmd) run() error {
+	failOn, err := parseLintFlags(lc.format, lc.failOn)
+	if err != nil {
+		return err
+	}
+
+	rules, err := policy.LoadPolicy(lc.policyDir, true)
+	if err != nil {
+		return err
+	}
+	files, err := readProjectFiles(lc.dest)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Dockerfile or deployment files found in %s", lc.dest)
+	}
+
+	input, err := policy.NewInput(files)
+	if err != nil {
+		return err
+	}
+	violations, err := policy.Evaluate(rules, input)
+	if err != nil {
+		return err
+	}
+	violations = append(violations, lintDockerfiles(input)...)
+
+	if err = printViolations(lc.out, lc.format, violations); err != nil {
+		return err
+	}
+	if policy.AnyAtLeast(violations, failOn) {
+		return fmt.Errorf("policy violations found with severity %s or higher", failOn)
+	}
+	return nil
+}
+
+// parseLintFlags validates the output format and returns the --fail-on severity
+func parseLintFlags(format, failOn string) (policy.Severity, error) {
+	if format != textFormat && format != string(JSON) {
+		return "", fmt.Errorf("invalid format %s, expected %s or %s", format, textFormat, JSON)
+	}
+	severity, err := policy.ParseSeverity(failOn)
+	if err != nil {
+		return "", fmt.Errorf("invalid --fail-on: %w", err)
+	}
+	return severity, nil
+}
+
+// lintDockerfiles runs the Dockerfile linter on the Dockerfiles of input, checking that they expose the
+// container ports of its manifests
+func lintDockerfiles(input policy.Input) []policy.Violation {
+	ports := doctor.ContainerPorts(input.Manifests)
+
+	var violations []policy.Violation
+	for _, source := range sortedKeys(input.Dockerfiles) {
+		violations = append(violations, dockerfilelint.Lint(source, input.Dockerfiles[source], dockerfilelint.Options{Ports: ports})...)
+	}
+	return violations
+}
+
+// printViolations prints the violations in the text or json format, with a summary
+func printViolations(out io.Writer, format string, violations []policy.Violation) error {
+	if format == string(JSON) {
+		result := lintResult{Violations: violations, Summary: policy.Summarize(violations)}
+		if result.Violations == nil {
+			result.Violations = []policy.Violation{}
+		}
+		resultText, err := json.MarshalIndent(result, "", TWO_SPACES)
+		if err != nil {
+			return fmt.Errorf("could not marshal lint result into json: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(resultText))
+		return err
+	}
+
+	for _, violation := range violations {
+		if _, err := fmt.Fprintln(out, violation.String()); err != nil {
+			return err
+		}
+	}
+	summary := policy.Summarize(violations)
+	_, err := fmt.Fprintf(out, "%d errors, %d warnings, %d info\n", summary.Errors, summary.Warnings, summary.Info)
+	return err
+}
+
+// readProjectFiles reads the Dockerfiles in the root of dest and the files in the directories of every deploy type,
+// such as its helm charts, kustomize base and overlays, and manifests directory, keyed by their slash separated path
+// relative to dest
+func readProjectFiles(dest string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !dockerfile.IsDockerfile(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dest, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = content
+	}
+
+	for _, backend := range backends.All() {
+		dirs, err := backend.Dirs(dest)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			if err = readFilesUnder(dest, dir, files); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+func readFilesUnder(dest, dir string, files map[string][]byte) error {
+	root := filepath.Join(dest, filepath.FromSlash(dir))
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(dest, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Debugf("no %s directory found in %s", dir, dest)
+		return nil
+	}
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	rootCmd.AddCommand(newLintCmd())
+}
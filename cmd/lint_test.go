@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+const testTeamLabelPolicy = `rules:
+  - id: require-team-label
+    severity: error
+    match:
+      kinds: [Deployment]
+    paths: [metadata.labels]
+    require: [team]
+`
+
+func TestLint(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	policyDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(policyDir, "team.yaml"), []byte(testTeamLabelPolicy), 0644))
+
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			dest := t.TempDir()
+			testCreateConfig := CreateConfig{DeployType: deployType, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+			mockCC := createCmd{dest: dest, createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}}
+			assert.Nil(t, mockCC.createDeployment())
+
+			var out bytes.Buffer
+			lc := lintCmd{dest: dest, format: string(JSON), failOn: string(policy.SeverityError), out: &out}
+			assert.Nil(t, lc.run())
+
+			// the default create output passes the built-in rules
+			var result lintResult
+			assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+			assert.Empty(t, result.Violations)
+			lc.failOn = string(policy.SeverityInfo)
+			assert.Nil(t, lc.run())
+
+			latestDest := t.TempDir()
+			testCreateConfig.DeployVariables = append(testCreateConfig.DeployVariables, UserInputs{Name: "IMAGETAG", Value: "latest"})
+			mockCC = createCmd{dest: latestDest, createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}}
+			assert.Nil(t, mockCC.createDeployment())
+
+			out.Reset()
+			lc = lintCmd{dest: latestDest, format: string(JSON), failOn: string(policy.SeverityError), out: &out}
+			assert.Nil(t, lc.run())
+			result = lintResult{}
+			assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+			assert.Zero(t, result.Summary.Errors)
+			assert.NotZero(t, result.Summary.Warnings)
+			for _, violation := range result.Violations {
+				assert.Equal(t, "no-latest-image-tag", violation.Rule)
+			}
+
+			lc.failOn = string(policy.SeverityWarning)
+			assert.NotNil(t, lc.run())
+
+			out.Reset()
+			lc = lintCmd{dest: dest, policyDir: policyDir, format: textFormat, failOn: string(policy.SeverityError), out: &out}
+			assert.NotNil(t, lc.run())
+			assert.Contains(t, out.String(), "[error] require-team-label")
+		})
+	}
+}
+
+func TestReadProjectFiles(t *testing.T) {
+	dest := t.TempDir()
+	for _, name := range []string{"Dockerfile", "Dockerfile.dev", "api.Dockerfile", "main.go"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dest, name), []byte("FROM alpine:3.18\n"), 0644))
+	}
+
+	files, err := readProjectFiles(dest)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Dockerfile", "Dockerfile.dev", "api.Dockerfile"}, maps.Keys(files))
+}
+
+func TestLintInvalidFlags(t *testing.T) {
+	dest := t.TempDir()
+	assert.NotNil(t, (&lintCmd{dest: dest, format: "xml", failOn: "error"}).run())
+	assert.NotNil(t, (&lintCmd{dest: dest, format: textFormat, failOn: "fatal"}).run())
+	assert.NotNil(t, (&lintCmd{dest: dest, format: textFormat, failOn: "error"}).run(), "a project without files should fail")
+}
+
+func TestCreateCheckPolicy(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	policyDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(policyDir, "team.yaml"), []byte(testTeamLabelPolicy), 0644))
+
+	templateWriter := &writers.FileMapWriter{}
+	testCreateConfig := CreateConfig{DeployType: "manifests", DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+	mockCC := createCmd{dest: ".", createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: templateWriter, policyDir: policyDir, policyFailOn: string(policy.SeverityError)}
+	assert.Nil(t, mockCC.createDeployment())
+
+	err := mockCC.checkPolicy(templateWriter.FileMap)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no files were written")
+
+	mockCC.policyFailOn = "fatal"
+	assert.NotNil(t, mockCC.checkPolicy(templateWriter.FileMap))
+
+	assert.Nil(t, os.Remove(filepath.Join(policyDir, "team.yaml")))
+	mockCC.policyFailOn = string(policy.SeverityError)
+	assert.Nil(t, mockCC.checkPolicy(templateWriter.FileMap))
+}
+
+func TestCreateDefaultsPassBuiltinPolicy(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			templateWriter := &writers.FileMapWriter{}
+			testCreateConfig := CreateConfig{DeployType: deployType, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+			mockCC := createCmd{dest: ".", createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: templateWriter, policyFailOn: string(policy.SeverityInfo)}
+			assert.Nil(t, mockCC.createDeployment())
+			assert.Nil(t, mockCC.checkPolicy(templateWriter.FileMap))
+		})
+	}
+}
+
+func TestLintDockerfile(t *testing.T) {
+	flagVariablesMap = map[string]string{}
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Dockerfile"), []byte("FROM alpine:3.18\nADD . /app\nEXPOSE 80\n"), 0644))
+
+	var out bytes.Buffer
+	ldc := lintDockerfileCmd{dest: dest, format: string(JSON), failOn: string(policy.SeverityError), out: &out}
+	assert.Nil(t, ldc.run(nil))
+	var result lintResult
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, policy.Summary{Warnings: 1, Info: 1}, result.Summary)
+
+	testCreateConfig := CreateConfig{DeployType: "manifests", DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testapp"}}}
+	mockCC := createCmd{dest: dest, createConfig: &testCreateConfig, skipEnvFile: true, templateWriter: &writers.LocalFSWriter{}}
+	assert.Nil(t, mockCC.createDeployment())
+
+	out.Reset()
+	ldc.format = textFormat
+	err := ldc.run(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, out.String(), "EXPOSE 80 doesn't match the deployment container port 8080")
+
+	ldc.ports = []string{"80"}
+	assert.Nil(t, ldc.run(nil))
+
+	assert.NotNil(t, ldc.run([]string{filepath.Join(dest, "missing.Dockerfile")}))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/templatescaffold"
+	"github.com/Azure/draft/pkg/templatetest"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+type templateNewCmd struct {
+	kind     string
+	provider string
+	root     string
+
+	templateWriter templatewriter.TemplateWriter
+	out            io.Writer
+}
+
+func newTemplateNewCmd() *cobra.Command {
+	nc := &templateNewCmd{templateWriter: &writers.LocalFSWriter{}, out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "new [flags] NAME",
+		Short: "Creates the skeleton of a new template pack",
+		Long: `This command creates a template pack named NAME in the directory of its kind under --dir, such as template/deployments/NAME or template/addons/azure/NAME.
+The pack has a draft.yaml documenting its fields, sample template files and a testdata directory with the values draft template test renders it with. The golden files in testdata/golden are written from the skeleton, so run draft template test --update after changing it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nc.run(args[0])
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&nc.kind, "kind", "k", "", "specify the kind of template pack (dockerfile, deployment, workflow, addon)")
+	f.StringVar(&nc.provider, "provider", templatescaffold.DefaultProvider, "specify the provider of an addon")
+	f.StringVarP(&nc.root, "dir", "d", "template", "specify the directory holding the template packs")
+	cmd.MarkFlagRequired("kind")
+
+	return cmd
+}
+
+func (nc *templateNewCmd) run(name string) error {
+	pack := templatescaffold.Pack{Kind: nc.kind, Name: name, Provider: nc.provider}
+	dir, err := templatescaffold.Create(nc.root, pack, nc.templateWriter)
+	if err != nil {
+		return err
+	}
+
+	violations, err := templatetest.Test(dir, templatetest.Options{UpdateGolden: true})
+	if err != nil {
+		return fmt.Errorf("testing template %s: %w", dir, err)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("created template %s has %d issues, see draft template test %s", dir, len(violations), dir)
+	}
+
+	_, err = fmt.Fprintf(nc.out, "Created %s template %s\n", nc.kind, dir)
+	return err
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatetest"
+)
+
+type templateTestCmd struct {
+	update bool
+	format string
+	failOn string
+
+	out io.Writer
+}
+
+func newTemplateTestCmd() *cobra.Command {
+	tc := &templateTestCmd{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "test [flags] DIR...",
+		Short: "Checks template packs and renders them with example values",
+		Long: `This command checks that every {{VAR}} a pack uses is declared in its draft.yaml and that every declared variable is used.
+It then renders the pack with the values in testdata/variables.yaml, the first example value of each variable or its default, checks that the rendered yaml files parse and the rendered Dockerfiles are valid, and compares the rendered files with the golden files in testdata/golden.
+Packs must be in a dockerfiles, deployments, workflows or addons/<provider> directory, such as template/deployments/helm. Pass --update to write the rendered files to testdata/golden.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tc.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&tc.update, "update", false, "write the rendered files to the golden files of the packs instead of comparing them")
+	f.StringVarP(&tc.format, "format", "o", textFormat, "specify the output format (text, json)")
+	f.StringVar(&tc.failOn, "fail-on", string(policy.SeverityError), "specify the lowest severity that fails the test (info, warning, error)")
+
+	return cmd
+}
+
+func (tc *templateTestCmd) run(dirs []string) error {
+	failOn, err := parseLintFlags(tc.format, tc.failOn)
+	if err != nil {
+		return err
+	}
+
+	var violations []policy.Violation
+	for _, dir := range dirs {
+		packViolations, err := templatetest.Test(dir, templatetest.Options{UpdateGolden: tc.update})
+		if err != nil {
+			return fmt.Errorf("testing template %s: %w", dir, err)
+		}
+		for _, violation := range packViolations {
+			violation.Source = filepath.Join(dir, violation.Source)
+			violations = append(violations, violation)
+		}
+	}
+
+	if err = printViolations(tc.out, tc.format, violations); err != nil {
+		return err
+	}
+	if policy.AnyAtLeast(violations, failOn) {
+		return fmt.Errorf("template test found issues with severity %s or higher", failOn)
+	}
+	return nil
+}
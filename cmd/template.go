@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Tools for authoring template packs",
+		Long: `These commands help authors of the Dockerfile, deployment, workflow and addon template packs kept under template/.
+A pack's directory holds its draft.yaml and template files, and a testdata directory that isn't rendered with example values and golden files for draft template test.`,
+	}
+
+	cmd.AddCommand(newTemplateNewCmd())
+	cmd.AddCommand(newTemplateTestCmd())
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTemplateCmd())
+}
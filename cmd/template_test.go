@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatetest"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestTemplateTest(t *testing.T) {
+	var out bytes.Buffer
+	tc := templateTestCmd{format: textFormat, failOn: string(policy.SeverityError), out: &out}
+	assert.Nil(t, tc.run([]string{"../template/dockerfiles/go", "../template/deployments/helm"}))
+	assert.Contains(t, out.String(), "0 errors")
+
+	dir := filepath.Join(t.TempDir(), "dockerfiles", "test")
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "draft.yaml"), []byte("variables:\n  - name: \"PORT\"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM test\nEXPOSE {{PORT}}\n"), 0644))
+
+	out.Reset()
+	tc = templateTestCmd{format: string(JSON), failOn: string(policy.SeverityError), out: &out}
+	assert.Nil(t, tc.run([]string{dir}))
+	var result lintResult
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, 1, result.Summary.Warnings)
+	assert.Len(t, result.Violations, 1)
+	assert.Equal(t, filepath.Join(dir, "draft.yaml"), result.Violations[0].Source)
+
+	tc.failOn = string(policy.SeverityWarning)
+	assert.NotNil(t, tc.run([]string{dir}))
+
+	assert.NotNil(t, tc.run([]string{t.TempDir()}))
+}
+
+func TestTemplateNew(t *testing.T) {
+	root := t.TempDir()
+	var out bytes.Buffer
+	nc := templateNewCmd{kind: "deployment", provider: "azure", root: root, templateWriter: &writers.LocalFSWriter{}, out: &out}
+	assert.Nil(t, nc.run("newdeploy"))
+	dir := filepath.Join(root, "deployments", "newdeploy")
+	assert.Contains(t, out.String(), "Created deployment template "+dir)
+	assert.FileExists(t, filepath.Join(dir, "testdata", "golden", "manifests", "deployment.yaml"))
+
+	tc := templateTestCmd{format: textFormat, failOn: string(policy.SeverityInfo), out: &out}
+	assert.Nil(t, tc.run([]string{dir}))
+
+	assert.NotNil(t, nc.run("newdeploy"))
+}
+
+func TestTemplateNewPassesLint(t *testing.T) {
+	for _, kind := range []string{templatetest.KindDockerfile, templatetest.KindDeployment} {
+		t.Run(kind, func(t *testing.T) {
+			root := t.TempDir()
+			var out bytes.Buffer
+			nc := templateNewCmd{kind: kind, provider: "azure", root: root, templateWriter: &writers.LocalFSWriter{}, out: &out}
+			assert.Nil(t, nc.run("newpack"))
+			dir := filepath.Join(root, templatetest.KindDirs[kind], "newpack")
+
+			tc := templateTestCmd{format: textFormat, failOn: string(policy.SeverityInfo), out: &out}
+			assert.Nil(t, tc.run([]string{dir}))
+
+			// the files draft create renders with the pack's defaults pass the built-in rules and the Dockerfile lint
+			pack, err := templatetest.Load(dir)
+			assert.Nil(t, err)
+			inputs, err := config.ResolveVariableDefaults(pack.Config.VariableDefaults, map[string]string{"APPNAME": "my-app"})
+			assert.Nil(t, err)
+			rendered, err := pack.Render(inputs)
+			assert.Nil(t, err)
+			dest := t.TempDir()
+			for file, content := range rendered {
+				assert.Nil(t, os.MkdirAll(filepath.Join(dest, filepath.Dir(file)), 0755))
+				assert.Nil(t, os.WriteFile(filepath.Join(dest, file), content, 0644))
+			}
+
+			out.Reset()
+			lc := lintCmd{dest: dest, format: textFormat, failOn: string(policy.SeverityInfo), out: &out}
+			assert.Nil(t, lc.run(), out.String())
+		})
+	}
+}
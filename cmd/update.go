@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,8 +12,10 @@ import (
 	"github.com/Azure/draft/pkg/addons"
 	"github.com/Azure/draft/pkg/config"
 	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/prompts"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/variables"
 	"github.com/Azure/draft/template"
 )
 
@@ -22,7 +23,10 @@ type updateCmd struct {
 	dest                     string
 	provider                 string
 	addon                    string
+	overlay                  string
+	chart                    string
 	flagVariables            []string
+	variableFile             string
 	userInputs               map[string]string
 	templateWriter           templatewriter.TemplateWriter
 	addonFS                  embed.FS
@@ -36,22 +40,25 @@ func newUpdateCmd() *cobra.Command {
 	// updateCmd represents the update command
 	var cmd = &cobra.Command{
 		Use:   "update",
-		Short: "Updates your application to be internet accessible",
-		Long: `This command automatically updates your yaml files as necessary so that your application
-		will be able to receive external requests.`,
+		Short: "Adds an addon, such as an ingress or an autoscaler, to your deployment files",
+		Long: `This command automatically updates your yaml files with an addon of a provider, such as an ingress
+		that lets your application receive external requests or an autoscaler.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := uc.run(); err != nil {
 				return err
 			}
-			log.Info("Draft has successfully updated your yaml files so that your application will be able to receive external requests 😃")
+			log.Infof("Draft has successfully added the %s addon to your yaml files 😃", uc.addon)
 			return nil
 		},
 	}
 	f := cmd.Flags()
 	f.StringVarP(&uc.dest, "destination", "d", ".", "specify the path to the project directory")
-	f.StringVarP(&uc.provider, "provider", "p", "azure", "cloud provider")
+	f.StringVarP(&uc.provider, "provider", "p", "azure", "specify the provider of the addon (azure, generic)")
 	f.StringVarP(&uc.addon, "addon", "a", "", "addon name")
-	f.StringArrayVarP(&uc.flagVariables, "variable", "", []string{}, "pass a variable non-interactively (ex: --variable foo=bar)")
+	f.StringArrayVarP(&uc.flagVariables, "variable", "", []string{}, "pass a variable non-interactively (ex: --variable foo=bar), read a value from a file with --variable foo=@path")
+	f.StringVar(&uc.variableFile, "variable-file", emptyDefaultFlagValue, "specify a yaml or .env file of variables, overridden by DRAFT_VAR_ environment variables and --variable flags")
+	f.StringVar(&uc.chart, "chart", emptyDefaultFlagValue, "specify the path to the helm chart to add the addon to (eg. charts/myapp)")
+	f.StringVar(&uc.overlay, "overlay", emptyDefaultFlagValue, "specify the kustomize overlay to add the addon to (eg. staging)")
 
 	uc.templateWriter = &writers.LocalFSWriter{}
 
@@ -59,14 +66,9 @@ func newUpdateCmd() *cobra.Command {
 }
 
 func (uc *updateCmd) run() error {
-	flagVariablesMap := make(map[string]string)
-	for _, flagVar := range uc.flagVariables {
-		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
-		if !ok {
-			return fmt.Errorf("invalid variable format: %s", flagVar)
-		}
-		flagVariablesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
+	flagVariablesMap, err := variables.Load(uc.flagVariables, uc.variableFile)
+	if err != nil {
+		return err
 	}
 
 	if uc.addon == "" {
@@ -82,6 +84,15 @@ func (uc *updateCmd) run() error {
 		return err
 	}
 
+	addonConfig.KustomizeOverlay, err = prompts.SelectKustomizeOverlay(uc.dest, uc.overlay)
+	if err != nil {
+		return err
+	}
+	addonConfig.HelmChart, err = prompts.SelectHelmChart(uc.dest, uc.chart)
+	if err != nil {
+		return err
+	}
+
 	uc.userInputs, err = addons.PromptAddonValues(uc.dest, flagVariablesMap, addonConfig)
 	if err != nil {
 		return err
@@ -97,7 +108,7 @@ func (uc *updateCmd) run() error {
 		}
 	}
 
-	err = addons.GenerateAddon(template.Addons, uc.provider, uc.addon, uc.dest, uc.userInputs, uc.templateWriter)
+	err = addons.GenerateAddon(template.Addons, uc.provider, uc.addon, uc.dest, addonConfig.KustomizeOverlay, addonConfig.HelmChart, uc.userInputs, uc.templateWriter)
 
 	if dryRun {
 		dryRunText, err := json.MarshalIndent(dryRunRecorder.DryRunInfo, "", TWO_SPACES)
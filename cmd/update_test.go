@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestUpdateVariablesOverrideDefaults(t *testing.T) {
+	tests := []struct {
+		addon     string
+		variables []string
+		addonFile string
+		want      []string
+	}{
+		{
+			addon:     "hpa",
+			variables: []string{"min-replicas=3", "max-replicas=5", "cpu-utilization=60"},
+			addonFile: "hpa.yaml",
+			want:      []string{"minReplicas: 3", "maxReplicas: 5", "averageUtilization: 60"},
+		},
+		{
+			addon:     "nginx_ingress",
+			variables: []string{"ingress-host=my-app.example.com", "ingress-path=/api", "ingress-class-name=custom"},
+			addonFile: "ingress.yaml",
+			want:      []string{"path: /api", "ingressClassName: custom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addon, func(t *testing.T) {
+			dest := t.TempDir()
+			assert.Nil(t, osutil.CopyDir(os.DirFS("../test/templates"), "manifests", dest, nil, nil, &writers.LocalFSWriter{}))
+
+			uc := updateCmd{dest: dest, provider: "generic", addon: tt.addon, flagVariables: tt.variables, templateWriter: &writers.LocalFSWriter{}}
+			assert.Nil(t, uc.run())
+
+			content, err := os.ReadFile(filepath.Join(dest, "manifests", tt.addonFile))
+			assert.Nil(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(content), want)
+			}
+		})
+	}
+}
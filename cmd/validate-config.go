@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/addons"
+	"github.com/Azure/draft/pkg/config"
+)
+
+const (
+	autoConfigType   = "auto"
+	draftConfigType  = "draft"
+	addonConfigType  = "addon"
+	createConfigType = "create"
+)
+
+// validatable is a config file that can check itself once decoded
+type validatable interface {
+	Validate() error
+}
+
+// configTypes returns a new value to decode each type of config file into, along with the title of its schema
+var configTypes = map[string]struct {
+	title string
+	new   func() validatable
+}{
+	draftConfigType:  {"Draft template config (draft.yaml)", func() validatable { return &config.DraftConfig{} }},
+	addonConfigType:  {"Draft addon config (draft.yaml)", func() validatable { return &addons.AddonConfig{} }},
+	createConfigType: {"Draft create config", func() validatable { return &CreateConfig{} }},
+}
+
+type validateConfigCmd struct {
+	configType string
+	schema     bool
+
+	out io.Writer
+}
+
+func newValidateConfigCmd() *cobra.Command {
+	vc := &validateConfigCmd{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "validate-config [flags] FILE...",
+		Short: "Validates draft.yaml template configs and draft create configs",
+		Long: `This command checks template configs (draft.yaml) and the configs passed to draft create --create-config for unknown fields, such as a misspelled deployVaraibles, values of the wrong type and variable defaults that can't be evaluated.
+Files named draft.yaml are validated as template configs, or addon configs when they have references, and any other file as a create config, unless --type is set.
+With --schema, the JSON Schema of the config type is printed instead.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if vc.schema {
+				return vc.printSchema()
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no config files to validate")
+			}
+			return vc.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&vc.configType, "type", "t", autoConfigType, "specify the type of the config files (auto, draft, addon, create)")
+	f.BoolVar(&vc.schema, "schema", false, "print the JSON Schema of the config type, draft if --type is auto")
+
+	return cmd
+}
+
+func (vc *validateConfigCmd) printSchema() error {
+	configType := vc.configType
+	if configType == autoConfigType {
+		configType = draftConfigType
+	}
+	schema, err := configSchema(configType)
+	if err != nil {
+		return err
+	}
+	_, err = vc.out.Write(schema)
+	return err
+}
+
+func (vc *validateConfigCmd) run(paths []string) error {
+	if _, ok := configTypes[vc.configType]; !ok && vc.configType != autoConfigType {
+		return fmt.Errorf("invalid config type %s, must be one of auto, draft, addon or create", vc.configType)
+	}
+
+	invalid := 0
+	for _, path := range paths {
+		if err := validateConfigFile(path, vc.configType); err != nil {
+			invalid++
+			fmt.Fprintf(vc.out, "%s: %s\n", path, err)
+			continue
+		}
+		fmt.Fprintf(vc.out, "%s: valid\n", path)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d config files are invalid", invalid, len(paths))
+	}
+	return nil
+}
+
+// validateConfigFile strictly decodes the config file and checks its values
+func validateConfigFile(path, configType string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if configType == autoConfigType {
+		configType = detectConfigType(path, content)
+	}
+
+	out := configTypes[configType].new()
+	if err = config.DecodeStrict(content, out); err != nil {
+		return err
+	}
+
+	return out.Validate()
+}
+
+// detectConfigType returns the type of a config file from its name, and for draft.yaml files whether they have references
+func detectConfigType(path string, content []byte) string {
+	if filepath.Base(path) != "draft.yaml" {
+		return createConfigType
+	}
+	var fields map[string]yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&fields); err == nil {
+		if _, ok := fields["references"]; ok {
+			return addonConfigType
+		}
+	}
+	return draftConfigType
+}
+
+// configSchema returns the JSON Schema of a type of config file
+func configSchema(configType string) ([]byte, error) {
+	t, ok := configTypes[configType]
+	if !ok {
+		return nil, fmt.Errorf("invalid config type %s, must be one of draft, addon or create", configType)
+	}
+	return config.MarshalJSONSchema(t.new(), t.title)
+}
+
+func init() {
+	rootCmd.AddCommand(newValidateConfigCmd())
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/template"
+)
+
+var configSchemaFiles = map[string]string{
+	draftConfigType:  "../test/draft_config_schema.json",
+	createConfigType: "../test/create_config_schema.json",
+}
+
+func TestConfigSchemasInSync(t *testing.T) {
+	for configType, schemaFile := range configSchemaFiles {
+		want, err := configSchema(configType)
+		assert.Nil(t, err)
+		got, err := os.ReadFile(schemaFile)
+		assert.Nil(t, err)
+		assert.Equal(t, string(want), string(got), "%s is out of date, regenerate it with draft validate-config --schema -t %s", schemaFile, configType)
+	}
+}
+
+func TestValidateTemplateConfigs(t *testing.T) {
+	schema, err := os.ReadFile(configSchemaFiles[draftConfigType])
+	assert.Nil(t, err)
+	schemaLoader := gojsonschema.NewBytesLoader(schema)
+
+	for _, templates := range []fs.FS{template.Dockerfiles, template.Deployments, template.Workflows, template.Addons} {
+		err := fs.WalkDir(templates, ".", func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || path.Base(filePath) != "draft.yaml" {
+				return err
+			}
+			content, err := fs.ReadFile(templates, filePath)
+			assert.Nil(t, err)
+
+			configType := detectConfigType(filePath, content)
+			out := configTypes[configType].new()
+			assert.Nil(t, config.DecodeStrict(content, out), filePath)
+			assert.Nil(t, out.Validate(), filePath)
+
+			if configType == draftConfigType {
+				document, err := yaml.YAMLToJSON(content)
+				assert.Nil(t, err)
+				result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewBytesLoader(document))
+				assert.Nil(t, err)
+				assert.True(t, result.Valid(), "%s doesn't match the schema: %v", filePath, result.Errors())
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "create.yaml")
+	assert.Nil(t, os.WriteFile(valid, []byte("deployType: helm\nlanguageType: go\ndeployVariables:\n  - name: PORT\n    value: \"8080\"\n"), 0644))
+	misspelled := filepath.Join(dir, "misspelled.yaml")
+	assert.Nil(t, os.WriteFile(misspelled, []byte("deployType: helm\ndeployVaraibles:\n  - name: PORT\n    value: \"8080\"\n"), 0644))
+	cycle := filepath.Join(dir, "draft.yaml")
+	assert.Nil(t, os.WriteFile(cycle, []byte("variables:\n  - name: A\n  - name: B\nvariableDefaults:\n  - name: A\n    value: \"{{B}}\"\n  - name: B\n    value: \"{{A}}\"\n"), 0644))
+
+	var out bytes.Buffer
+	vc := validateConfigCmd{configType: autoConfigType, out: &out}
+	assert.Nil(t, vc.run([]string{valid}))
+	assert.Contains(t, out.String(), "create.yaml: valid")
+
+	out.Reset()
+	assert.NotNil(t, vc.run([]string{valid, misspelled, cycle}))
+	assert.Contains(t, out.String(), "line 2: field deployVaraibles not found")
+	assert.Contains(t, out.String(), "variable defaults have a cycle: A -> B -> A")
+
+	out.Reset()
+	vc.configType = "helm"
+	assert.NotNil(t, vc.run([]string{valid}))
+}
+
+func TestInitConfigStrict(t *testing.T) {
+	misspelled := filepath.Join(t.TempDir(), "create.yaml")
+	assert.Nil(t, os.WriteFile(misspelled, []byte("deployType: helm\nlanguageTyep: go\n"), 0644))
+
+	cc := createCmd{createConfigPath: misspelled}
+	err := cc.initConfig()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2: field languageTyep not found")
+}
@@ -13,7 +13,7 @@ import (
 func WriteDeploymentFiles(w templatewriter.TemplateWriter, deploymentOutputPath string, deploymentInputs map[string]string, deploymentType string) error {
 	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, deploymentOutputPath)
 
-	err := d.CopyDeploymentFiles(deploymentType, deploymentInputs, w)
+	err := d.CopyDeploymentFiles(deploymentType, nil, deploymentInputs, w)
 	if err != nil {
 		return fmt.Errorf("failed to generate manifest: %e", err)
 	}
@@ -35,12 +35,19 @@ func WriteDeploymentFilesExample() error {
 
 	// Create a map of inputs to the template (must correspond to the inputs in the template/deployments/<deploymentType>/draft.yaml file)
 	deploymentInputs := map[string]string{
-		"PORT":        "8080",
-		"APPNAME":     "example-app",
-		"SERVICEPORT": "8080",
-		"NAMESPACE":   "example-namespace",
-		"IMAGENAME":   "example-image",
-		"IMAGETAG":    "latest",
+		"PORT":         "8080",
+		"APPNAME":      "example-app",
+		"SERVICEPORT":  "8080",
+		"NAMESPACE":    "example-namespace",
+		"IMAGENAME":    "example-image",
+		"IMAGETAG":     "latest",
+		"PROBEPATH":    "/",
+		"PROBEPORT":    "http",
+		"CPUREQ":       "100m",
+		"CPULIMIT":     "500m",
+		"MEMREQ":       "128Mi",
+		"MEMLIMIT":     "512Mi",
+		"RUNASNONROOT": "true",
 	}
 
 	// Set the output path for the deployment files
@@ -20,12 +20,27 @@ func TestWriteDeploymentFiles(t *testing.T) {
 		{
 			name: "Test Valid Manifests Deployment Generation",
 			inputVariables: map[string]string{
-				"PORT":        "8080",
-				"APPNAME":     "testapp",
-				"SERVICEPORT": "8080",
-				"NAMESPACE":   "testnamespace",
-				"IMAGENAME":   "testimage",
-				"IMAGETAG":    "latest",
+				"PORT":         "8080",
+				"APPNAME":      "testapp",
+				"SERVICEPORT":  "8080",
+				"NAMESPACE":    "testnamespace",
+				"IMAGENAME":    "testimage",
+				"IMAGETAG":     "latest",
+				"PROBEPATH":    "/",
+				"PROBEPORT":    "http",
+				"CPUREQ":       "100m",
+				"CPULIMIT":     "500m",
+				"MEMREQ":       "128Mi",
+				"MEMLIMIT":     "512Mi",
+				"RUNASNONROOT": "true",
+			},
+			deploymentType: "manifests",
+			expectError:    false,
+		},
+		{
+			name: "Test Manifests Deployment Generation With Default Variables",
+			inputVariables: map[string]string{
+				"APPNAME": "testapp",
 			},
 			deploymentType: "manifests",
 			expectError:    false,
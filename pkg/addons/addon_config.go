@@ -4,146 +4,120 @@ import (
 	"errors"
 	"fmt"
 	"path"
-	"strings"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
-	"helm.sh/helm/v3/pkg/chart/loader"
-	"sigs.k8s.io/kustomize/api/krusty"
-	"sigs.k8s.io/kustomize/api/types"
-	"sigs.k8s.io/kustomize/kyaml/filesys"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"golang.org/x/exp/maps"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/consts"
-	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/templatewriter"
 )
 
 // AddonConfig is a struct that extends the base DraftConfig to allow for the Referencing previously generated
 // k8s objects. This allows an addon creator to reference pre-entered data from the deployment files.
 type AddonConfig struct {
-	config.DraftConfig  `yaml:",inline"`
-	ReferenceComponents map[string][]referenceResource `yaml:"references"`
+	config.DraftConfig `yaml:",inline"`
+	// ReferenceComponents are the references of the addon by the resource they're read from: a kind, such as service
+	// or deployment, optionally followed by /name to choose between several resources of that kind
+	ReferenceComponents map[string][]backends.Reference `yaml:"references"`
+	// ScalesDeployment is set for autoscalers of the deployment, which then stops setting its replicas
+	ScalesDeployment bool `yaml:"scalesDeployment"`
+	// ScalingValues are the variables of an autoscaler that helm charts keep in their values
+	ScalingValues []ScalingValue `yaml:"scalingValues"`
+	// KustomizeOverlay is the overlay in the overlays directory that kustomize addons are written to and
+	// references are read from. Defaults to production.
+	KustomizeOverlay string `yaml:"-"`
+	// HelmChart is the path, relative to the destination, of the chart that helm addons are written to and
+	// references are read from. Defaults to the only chart found in the destination.
+	HelmChart string `yaml:"-"`
 
 	deployType string
 }
 
-type referenceResource struct {
-	Name string
-	Path string
-}
-
-type Reference interface {
-	GetReferenceVariables([]referenceResource) map[string]string
-}
-
-func (ac *AddonConfig) getDeployType(dest string) (string, error) {
-	if ac.deployType != "" {
-		return ac.deployType, nil
-	}
-	deploymentType, err := filematches.FindDraftDeploymentFiles(dest)
-	log.Debugf("found deployment type: %s", deploymentType)
-	return deploymentType, err
-}
-
-func (ac *AddonConfig) GetAddonDestPath(dest string) (string, error) {
-	deployType, err := ac.getDeployType(dest)
-	if err != nil {
-		return "", err
+// Validate checks the addon's variables and the paths of its references
+func (ac *AddonConfig) Validate() error {
+	if err := ac.DraftConfig.Validate(); err != nil {
+		return err
 	}
-	return path.Join(dest, consts.DeploymentFilePaths[deployType]), err
-}
-
-// GetReferenceValueMap extracts k8s object values into a mapping of template strings to k8s object value.
-func (ac *AddonConfig) GetReferenceValueMap(dest string) (map[string]string, error) {
-	referenceMap := make(map[string]string)
-
-	deployType, err := ac.getDeployType(dest)
-
-	for referenceName, referenceResources := range ac.ReferenceComponents {
-		switch deployType {
-		case "helm":
-			if err = extractHelmValuesToMap(referenceName, dest, referenceResources, referenceMap); err != nil {
-				return nil, err
-			}
-
-		case "kustomize":
-			if err = extractKustomizeValuesToMap(referenceName, dest, referenceResources, referenceMap); err != nil {
-				return nil, err
-			}
-
-		case "manifests":
-			if err = extractManifestValuesToMap(referenceName, dest, referenceResources, referenceMap); err != nil {
-				return nil, err
+	resources := maps.Keys(ac.ReferenceComponents)
+	sort.Strings(resources)
+	for _, resource := range resources {
+		for _, reference := range ac.ReferenceComponents[resource] {
+			if err := reference.Validate(); err != nil {
+				return fmt.Errorf("%s %w", resource, err)
 			}
 		}
 	}
-
-	return referenceMap, err
+	if len(ac.ScalingValues) > 0 && !ac.ScalesDeployment {
+		return errors.New("scalingValues are only used by addons that set scalesDeployment")
+	}
+	variables := make(map[string]bool, len(ac.Variables))
+	for _, variable := range ac.Variables {
+		variables[variable.Name] = true
+	}
+	for _, value := range ac.ScalingValues {
+		if value.Path == "" || !variables[value.Name] {
+			return fmt.Errorf("scaling value %s needs a path and one of the addon's variables", value.Name)
+		}
+	}
+	return nil
 }
 
-// TODO: should consolidate all deployTypes into single interface to abstract the implementations
-func extractHelmValuesToMap(referenceName, dest string, references []referenceResource, referenceMap map[string]string) error {
-	chart, err := loader.Load(dest + "/charts/")
+// getBackend returns the backend of the addon's deploy type, detecting it from the deployment files in dest
+func (ac *AddonConfig) getBackend(dest string) (backends.DeploymentBackend, error) {
+	if ac.deployType != "" {
+		return backends.Get(ac.deployType)
+	}
+	backend, err := backends.Detect(dest)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	log.Debugf("found deployment type: %s", backend.DeployType())
+	return backend, nil
+}
 
-	for _, reference := range references {
-		referenceMap[reference.Name] =
-			strings.ReplaceAll(
-				consts.HelmReferencePathMapping[referenceName][reference.Path], "{{APPNAME}}", chart.Name())
+// target selects the chart or overlay the addon is written to
+func (ac *AddonConfig) target() backends.Target {
+	target := backends.Target{HelmChart: ac.HelmChart}
+	if ac.KustomizeOverlay != "" {
+		target.KustomizeOverlay = path.Join(consts.KustomizeOverlaysDir, ac.KustomizeOverlay)
 	}
-
-	return nil
+	return target
 }
 
-func extractKustomizeValuesToMap(referenceName, dest string, references []referenceResource, referenceMap map[string]string) error {
-	kustomizer := krusty.MakeKustomizer(&krusty.Options{PluginConfig: &types.PluginConfig{}})
-	production, err := kustomizer.Run(filesys.FileSystemOrOnDisk{}, dest+"/overlays/production/")
+func (ac *AddonConfig) GetAddonDestPath(dest string) (string, error) {
+	backend, err := ac.getBackend(dest)
 	if err != nil {
-		return err
+		return "", err
 	}
-	rNodes := production.ToRNodeSlice()
-
-	return extractNativeRefMap(rNodes, referenceName, references, referenceMap)
+	return backend.AddonDestPath(dest, ac.target())
 }
 
-func extractManifestValuesToMap(referenceName, dest string, references []referenceResource, referenceMap map[string]string) error {
-	serviceYaml, err := yaml.ReadFile(dest + "/manifests/service.yaml")
+// AddAddonFiles adds the addon files, relative to the addon destination path, to the resources deployed from dest
+func (ac *AddonConfig) AddAddonFiles(dest string, files []string, templateWriter templatewriter.TemplateWriter) error {
+	backend, err := ac.getBackend(dest)
 	if err != nil {
 		return err
 	}
-	rNodes := make([]*yaml.RNode, 0)
-	rNodes = append(rNodes, serviceYaml)
-	return extractNativeRefMap(rNodes, referenceName, references, referenceMap)
+	return backend.AddAddonFiles(dest, ac.target(), files, templateWriter)
 }
 
-func extractNativeRefMap(referenceNodes []*yaml.RNode, referenceName string, references []referenceResource, referenceMap map[string]string) error {
-	for _, reference := range references {
-		refStr := extractRef(referenceNodes, consts.RefPathLookups[referenceName][reference.Path])
-		if refStr == "" && strings.Contains(reference.Name, "namespace") {
-			//hack for default namespace
-			refStr = "default"
-		} else if refStr == "" {
-			return errors.New(fmt.Sprintf("referenceResource %s not found", reference.Name))
-		}
-
-		referenceMap[reference.Name] = refStr
+// GetReferenceValueMap extracts k8s object values into a mapping of template strings to k8s object value.
+func (ac *AddonConfig) GetReferenceValueMap(dest string) (map[string]string, error) {
+	backend, err := ac.getBackend(dest)
+	if err != nil {
+		return nil, err
 	}
-	return nil
-}
 
-func extractRef(rNodes []*yaml.RNode, lookupPath []string) string {
-	for _, rNode := range rNodes {
-		ref, err := rNode.Pipe(yaml.Lookup(lookupPath...))
-		if ref == nil || err != nil {
-			continue
+	referenceMap := make(map[string]string)
+	for referenceName, referenceResources := range ac.ReferenceComponents {
+		values, err := backend.ReferenceValues(dest, ac.target(), referenceName, referenceResources)
+		if err != nil {
+			return nil, err
 		}
-
-		refYNode := ref.YNode() // Convert to go yaml.node to extract values without trailing newlines
-		refStr := refYNode.Value
-		log.Debugf("found ref: %s", refStr)
-		return refStr
+		maps.Copy(referenceMap, values)
 	}
-	return ""
+	return referenceMap, nil
 }
@@ -2,11 +2,14 @@ package addons
 
 import (
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/template"
 )
 
@@ -18,8 +21,8 @@ func TestGetHelmReferenceMap(t *testing.T) {
 	err = yaml.Unmarshal(configBytes, &addOnConfig)
 	assert.Nil(t, err)
 
-	refMap := make(map[string]string)
-	err = extractHelmValuesToMap("service", "../../test/templates/helm", addOnConfig.ReferenceComponents["service"], refMap)
+	addOnConfig.deployType = "helm"
+	refMap, err := addOnConfig.GetReferenceValueMap("../../test/templates/helm")
 	assert.Nil(t, err)
 	assert.NotEmpty(t, refMap)
 }
@@ -30,9 +33,8 @@ func TestGetKustomizeReferenceMap(t *testing.T) {
 
 	var addOnConfig AddonConfig
 	err = yaml.Unmarshal(configBytes, &addOnConfig)
-	refMap := make(map[string]string)
-
-	err = extractKustomizeValuesToMap("service", "../../test/templates/kustomize", addOnConfig.ReferenceComponents["service"], refMap)
+	addOnConfig.deployType = "kustomize"
+	refMap, err := addOnConfig.GetReferenceValueMap("../../test/templates/kustomize")
 	assert.Nil(t, err)
 	assert.NotEmpty(t, refMap)
 }
@@ -43,9 +45,45 @@ func TestGetManifestReferenceMap(t *testing.T) {
 
 	var addOnConfig AddonConfig
 	err = yaml.Unmarshal(configBytes, &addOnConfig)
-	refMap := make(map[string]string)
-
-	err = extractManifestValuesToMap("service", "../../test/templates/manifests", addOnConfig.ReferenceComponents["service"], refMap)
+	addOnConfig.deployType = "manifests"
+	refMap, err := addOnConfig.GetReferenceValueMap("../../test/templates/manifests")
 	assert.Nil(t, err)
 	assert.NotEmpty(t, refMap)
 }
+
+func TestGetHelmAddonDestPath(t *testing.T) {
+	dest := t.TempDir()
+	for _, chart := range []string{"charts/api", "charts/api/charts/subchart", "charts/web"} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dest, chart), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dest, chart, "Chart.yaml"), []byte("name: test"), 0644))
+	}
+
+	addOnConfig := AddonConfig{deployType: "helm"}
+	_, err := addOnConfig.GetAddonDestPath(dest)
+	assert.NotNil(t, err)
+
+	addOnConfig.HelmChart = "charts/web"
+	destPath, err := addOnConfig.GetAddonDestPath(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dest, "charts/web/templates"), destPath)
+
+	assert.Nil(t, os.RemoveAll(filepath.Join(dest, "charts/web")))
+	addOnConfig.HelmChart = ""
+	destPath, err = addOnConfig.GetAddonDestPath(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dest, "charts/api/templates"), destPath)
+}
+
+func TestAddonConfigValidate(t *testing.T) {
+	configBytes, err := fs.ReadFile(template.Addons, "addons/azure/webapp_routing/draft.yaml")
+	assert.Nil(t, err)
+
+	var addOnConfig AddonConfig
+	assert.Nil(t, yaml.Unmarshal(configBytes, &addOnConfig))
+	assert.Nil(t, addOnConfig.Validate())
+
+	addOnConfig.ReferenceComponents["deployment"] = []backends.Reference{{Name: "container-port", Path: "spec.template.spec.containers[name=app.ports"}}
+	err = addOnConfig.Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "deployment reference container-port: invalid reference path")
+}
@@ -6,28 +6,33 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/manifoldco/promptui"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
+	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/embedutils"
 	"github.com/Azure/draft/pkg/osutil"
 	"github.com/Azure/draft/pkg/prompts"
 	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
 )
 
 var (
 	parentDirName = "addons"
 )
 
-func GenerateAddon(addons embed.FS, provider, addon, dest string, userInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+func GenerateAddon(addons embed.FS, provider, addon, dest, kustomizeOverlay, helmChart string, userInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	addOnConfig, err := GetAddonConfig(addons, provider, addon)
 	if err != nil {
 		return err
 	}
+	addOnConfig.KustomizeOverlay = kustomizeOverlay
+	addOnConfig.HelmChart = helmChart
 
 	selectedAddonPath, err := GetAddonPath(addons, provider, addon)
 	if err != nil {
@@ -39,11 +44,52 @@ func GenerateAddon(addons embed.FS, provider, addon, dest string, userInputs map
 		return err
 	}
 
-	if err = osutil.CopyDir(addons, selectedAddonPath, addonDestPath, &addOnConfig.DraftConfig, userInputs, templateWriter); err != nil {
+	// the deployment files are only changed once the addon has rendered
+	scalingWriter := &writers.FileMapWriter{}
+	inputs, err := addOnConfig.ScaleDeployment(dest, userInputs, scalingWriter)
+	if err != nil {
 		return err
 	}
 
-	return err
+	fileMapWriter := &writers.FileMapWriter{}
+	if err = osutil.CopyDir(addons, selectedAddonPath, addonDestPath, &addOnConfig.DraftConfig, inputs, fileMapWriter); err != nil {
+		return err
+	}
+	if err = writers.WriteFileMap(fileMapWriter.FileMap, templateWriter); err != nil {
+		return err
+	}
+	if err = writers.WriteFileMap(scalingWriter.FileMap, templateWriter); err != nil {
+		return err
+	}
+
+	var addonFiles []string
+	for filePath := range fileMapWriter.FileMap {
+		relPath, err := filepath.Rel(addonDestPath, filePath)
+		if err != nil {
+			return err
+		}
+		addonFiles = append(addonFiles, filepath.ToSlash(relPath))
+	}
+	sort.Strings(addonFiles)
+	return addOnConfig.AddAddonFiles(dest, addonFiles, templateWriter)
+}
+
+// Providers returns the providers that have addons
+func Providers(addons embed.FS) ([]string, error) {
+	providerMap, err := embedutils.EmbedFStoMap(addons, parentDirName)
+	if err != nil {
+		return nil, err
+	}
+	return maps.Keys(providerMap), nil
+}
+
+// Names returns the names of the addons of provider
+func Names(addons embed.FS, provider string) ([]string, error) {
+	addonMap, err := embedutils.EmbedFStoMap(addons, path.Join(parentDirName, strings.ToLower(provider)))
+	if err != nil {
+		return nil, err
+	}
+	return maps.Keys(addonMap), nil
 }
 
 func GetAddonPath(addons embed.FS, provider, addon string) (string, error) {
@@ -77,8 +123,8 @@ func GetAddonConfig(addons embed.FS, provider, addon string) (AddonConfig, error
 		return AddonConfig{}, err
 	}
 	var addOnConfig AddonConfig
-	if err = yaml.Unmarshal(configBytes, &addOnConfig); err != nil {
-		return AddonConfig{}, err
+	if err = config.DecodeStrict(configBytes, &addOnConfig); err != nil {
+		return AddonConfig{}, fmt.Errorf("%s: %w", addOnConfigPath, err)
 	}
 
 	return addOnConfig, nil
@@ -108,9 +154,8 @@ func PromptAddonValues(dest string, userInputs map[string]string, addOnConfig Ad
 	log.Debugf("getAddonValues: %s", userInputs)
 	var err error
 
-	inputsToSkip := maps.Keys(userInputs)
-	log.Debugf("inputsToSkip: %s", inputsToSkip)
-	promptInputs, err := prompts.RunPromptsFromConfigWithSkips(&addOnConfig.DraftConfig, inputsToSkip)
+	// the variables set by the user aren't prompted for, and keep their values rather than their defaults
+	promptInputs, err := prompts.RunPromptsFromConfigWithInputs(&addOnConfig.DraftConfig, userInputs)
 	if err != nil {
 		return nil, err
 	}
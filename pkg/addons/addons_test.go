@@ -1,12 +1,19 @@
 package addons
 
 import (
+	"io/fs"
 	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/maps"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/pkg/osutil"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/template"
@@ -14,18 +21,20 @@ import (
 
 const templatePath = "../../test/templates"
 
+var kindPattern = regexp.MustCompile(`(?m)^kind: \S+$`)
+
 func TestGenerateAddonErrors(t *testing.T) {
 	templateWriter := &writers.LocalFSWriter{}
 	userInputs := map[string]string{
 		"test": "test",
 	}
-	err := GenerateAddon(template.Addons, "azure", "webapp_routing", "fakeDest", userInputs, templateWriter)
+	err := GenerateAddon(template.Addons, "azure", "webapp_routing", "fakeDest", "", "", userInputs, templateWriter)
 	assert.NotNil(t, err, "should fail with fake destination")
 
-	err = GenerateAddon(template.Addons, "azure", "fakeAddon", "../../test/templates/helm", userInputs, templateWriter)
+	err = GenerateAddon(template.Addons, "azure", "fakeAddon", "../../test/templates/helm", "", "", userInputs, templateWriter)
 	assert.NotNil(t, err, "should fail with fake addon name")
 
-	err = GenerateAddon(template.Addons, "fakeProvider", "fakeAddon", "../../test/templates/helm", userInputs, templateWriter)
+	err = GenerateAddon(template.Addons, "fakeProvider", "fakeAddon", "../../test/templates/helm", "", "", userInputs, templateWriter)
 	assert.NotNil(t, err, "should fail with fake provider name")
 }
 
@@ -42,7 +51,7 @@ func TestGenerateHelmAddonSuccess(t *testing.T) {
 	dir, remove, err := setUpTempDir("helm")
 	assert.Nil(t, err)
 
-	err = GenerateAddon(template.Addons, "azure", "webapp_routing", dir, correctUserInputs, templateWriter)
+	err = GenerateAddon(template.Addons, "azure", "webapp_routing", dir, "", "", correctUserInputs, templateWriter)
 	assert.Nil(t, err)
 
 	assert.Nil(t, remove())
@@ -61,12 +70,31 @@ func TestGenerateKustomizeAddonSuccess(t *testing.T) {
 	dir, remove, err := setUpTempDir("kustomize")
 	assert.Nil(t, err)
 
-	err = GenerateAddon(template.Addons, "azure", "webapp_routing", dir, correctUserInputs, templateWriter)
+	err = GenerateAddon(template.Addons, "azure", "webapp_routing", dir, "", "", correctUserInputs, templateWriter)
 	assert.Nil(t, err)
 
 	assert.Nil(t, remove())
 }
 
+func TestGenerateScalingAddonFailureKeepsDeployment(t *testing.T) {
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			dir, remove, err := setUpTempDir(deployType)
+			assert.Nil(t, err)
+			defer remove()
+			before, err := readDir(dir)
+			assert.Nil(t, err)
+
+			// the hpa's references are missing, so it fails to render
+			err = GenerateAddon(template.Addons, "generic", "hpa", dir, "", "", map[string]string{"min-replicas": "2"}, &writers.LocalFSWriter{})
+			assert.NotNil(t, err)
+			after, err := readDir(dir)
+			assert.Nil(t, err)
+			assert.Equal(t, before, after)
+		})
+	}
+}
+
 func setUpTempDir(deploy string) (dir string, close func() error, err error) {
 	templateWriter := &writers.LocalFSWriter{}
 	dir, err = ioutil.TempDir("", "addonTest")
@@ -83,3 +111,156 @@ func setUpTempDir(deploy string) (dir string, close func() error, err error) {
 
 	return dir, close, err
 }
+
+func TestProvidersAndNames(t *testing.T) {
+	providers, err := Providers(template.Addons)
+	assert.Nil(t, err)
+	assert.Contains(t, providers, "azure")
+	assert.Contains(t, providers, "generic")
+
+	names, err := Names(template.Addons, "Azure")
+	assert.Nil(t, err)
+	assert.Contains(t, names, "webapp_routing")
+
+	_, err = Names(template.Addons, "fakeProvider")
+	assert.NotNil(t, err)
+}
+
+func TestGenerateGenericAddons(t *testing.T) {
+	replicas := map[string]string{"min-replicas": "2", "max-replicas": "5"}
+	addonInputs := map[string]map[string]string{
+		"nginx_ingress":        {"ingress-host": "my-app.example.com"},
+		"cert_manager_tls":     {"ingress-host": "my-app.example.com", "cert-manager-issuer": "letsencrypt-prod"},
+		"traefik_ingressroute": {"ingress-host": "my-app.example.com"},
+		"gateway_httproute":    {"ingress-host": "my-app.example.com", "gateway-name": "my-gateway", "gateway-namespace": "gateway-system"},
+		"hpa":                  replicas,
+		"keda_queue":           mergeInputs(replicas, map[string]string{"keda-queue-name": "orders"}),
+		"keda_http":            mergeInputs(replicas, map[string]string{"ingress-host": "my-app.example.com"}),
+	}
+	names, err := Names(template.Addons, "generic")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, maps.Keys(addonInputs), names)
+
+	// the Service and Deployment of the test projects have the same name
+	deployments := []struct {
+		deployType  string
+		addonDir    string
+		name        string
+		namespace   string
+		servicePort string
+		// rendered are the rendered deployment files of the target the addon is added to
+		rendered string
+	}{
+		{deployType: "helm", addonDir: "charts/templates", rendered: "charts", name: `{{ include "test.fullname" . }}`, namespace: "{{ .Release.Namespace }}", servicePort: "{{ .Values.service.port }}"},
+		{deployType: "kustomize", addonDir: "overlays/production", rendered: "overlays/production", name: "test", namespace: "default", servicePort: "80"},
+		{deployType: "manifests", addonDir: "manifests", rendered: "manifests", name: "test", namespace: "default", servicePort: "80"},
+	}
+	for addon, inputs := range addonInputs {
+		for _, deployment := range deployments {
+			t.Run(addon+"/"+deployment.deployType, func(t *testing.T) {
+				dir, remove, err := setUpTempDir(deployment.deployType)
+				assert.Nil(t, err)
+				defer remove()
+
+				addOnConfig, err := GetAddonConfig(template.Addons, "generic", addon)
+				assert.Nil(t, err)
+				userInputs, err := PromptAddonValues(dir, maps.Clone(inputs), addOnConfig)
+				assert.Nil(t, err)
+				for _, reference := range []string{"service-name", "deployment-name"} {
+					if value, ok := userInputs[reference]; ok {
+						assert.Equal(t, deployment.name, value)
+					}
+				}
+				for _, reference := range []string{"service-namespace", "deployment-namespace"} {
+					if value, ok := userInputs[reference]; ok {
+						assert.Equal(t, deployment.namespace, value)
+					}
+				}
+				if value, ok := userInputs["service-port"]; ok {
+					assert.Equal(t, deployment.servicePort, value)
+				}
+
+				assert.Nil(t, GenerateAddon(template.Addons, "generic", addon, dir, "", "", userInputs, &writers.LocalFSWriter{}))
+				addonFiles, err := fs.Glob(template.Addons, path.Join("addons/generic", addon, "*.yaml"))
+				assert.Nil(t, err)
+				for _, addonFile := range addonFiles {
+					if path.Base(addonFile) == "draft.yaml" {
+						continue
+					}
+					content, err := os.ReadFile(filepath.Join(dir, deployment.addonDir, path.Base(addonFile)))
+					assert.Nil(t, err)
+					assert.Contains(t, string(content), "name: "+deployment.name)
+					assert.Empty(t, osutil.TemplateVariables(string(content)), "%s has unreplaced variables", addonFile)
+				}
+
+				backend, err := backends.Get(deployment.deployType)
+				assert.Nil(t, err)
+				files, err := readDir(dir)
+				assert.Nil(t, err)
+				rendered, err := backend.Render(files)
+				assert.Nil(t, err)
+				var built string
+				for source, manifests := range rendered {
+					if source == deployment.rendered || strings.HasPrefix(source, deployment.rendered+"/") {
+						built += string(manifests) + "\n---\n"
+					}
+				}
+				if addOnConfig.ScalesDeployment {
+					for _, resource := range strings.Split(built, "\n---\n") {
+						if kindPattern.FindString(resource) == "kind: Deployment" {
+							assert.NotContains(t, resource, "\n  replicas:", "the deployment %s scales sets its replicas", addon)
+						}
+					}
+				}
+				if addon == "hpa" {
+					assert.Contains(t, built, "minReplicas: 2")
+					if deployment.deployType == "kustomize" {
+						assert.Contains(t, built, "kind: Deployment\n    name: production-test\n", "the hpa scales the overlay's deployment")
+					}
+				}
+
+				if deployment.deployType == "kustomize" {
+					// the overlay prefixes the names of the addon's resources once, like those of the base
+					assert.Contains(t, built, "name: production-test\n")
+					assert.NotContains(t, built, "production-production-")
+					for _, addonFile := range addonFiles {
+						if path.Base(addonFile) == "draft.yaml" {
+							continue
+						}
+						addonResource, err := fs.ReadFile(template.Addons, addonFile)
+						assert.Nil(t, err)
+						kind := kindPattern.FindString(string(addonResource))
+						assert.NotEmpty(t, kind)
+						assert.Contains(t, built, kind, "the overlay doesn't build %s", addonFile)
+					}
+				}
+			})
+		}
+	}
+}
+
+func mergeInputs(inputs ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, input := range inputs {
+		maps.Copy(merged, input)
+	}
+	return merged
+}
+
+// readDir returns the files in dir, keyed by their slash separated path relative to dir
+func readDir(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		files[filepath.ToSlash(relPath)] = content
+		return err
+	})
+	return files, err
+}
@@ -0,0 +1,319 @@
+package addons
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/backends"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// helmAutoscalingEnabled is the value that turns autoscaling on in the charts draft generates
+const helmAutoscalingEnabled = "autoscaling.enabled"
+
+// ScalingValue is a variable of an addon scaling the deployment that helm charts keep in their values
+type ScalingValue struct {
+	Name string `yaml:"name"`
+	// Path is the field of the chart's values.yaml the variable is kept at, such as autoscaling.minReplicas
+	Path string `yaml:"path"`
+}
+
+// ScaleDeployment hands the replicas of the deployment in dest over to the addon, when it's an autoscaler, and returns
+// inputs with the expressions the addon reads the scaling values the deployment files keep from in their place
+func (ac *AddonConfig) ScaleDeployment(dest string, inputs map[string]string, templateWriter templatewriter.TemplateWriter) (map[string]string, error) {
+	if !ac.ScalesDeployment {
+		return inputs, nil
+	}
+	backend, err := ac.getBackend(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(ac.ScalingValues))
+	for _, value := range ac.ScalingValues {
+		values[value.Path] = inputs[value.Name]
+	}
+	expressions, err := scaleDeployment(backend, dest, ac.target(), values, templateWriter)
+	if err != nil {
+		return nil, fmt.Errorf("scaling the deployment: %w", err)
+	}
+
+	scaledInputs := maps.Clone(inputs)
+	for _, value := range ac.ScalingValues {
+		if expression, ok := expressions[value.Path]; ok {
+			scaledInputs[value.Name] = expression
+		}
+	}
+	return scaledInputs, nil
+}
+
+// scaleDeployment stops the target's Deployment from setting its replicas, which the autoscaler manages instead.
+// values are settings of the autoscaler, such as its minimum replicas, by the path of the chart values they're kept
+// at. It returns the expressions the autoscaler reads the values it keeps from, by path.
+func scaleDeployment(backend backends.DeploymentBackend, dest string, target backends.Target, values map[string]string, templateWriter templatewriter.TemplateWriter) (map[string]string, error) {
+	addonDestPath, err := backend.AddonDestPath(dest, target)
+	if err != nil {
+		return nil, err
+	}
+	switch backend.(type) {
+	case *backends.Helm:
+		// helm addons are written to the templates directory of the chart
+		return scaleHelmChart(path.Dir(addonDestPath), values, templateWriter)
+	case *backends.Kustomize:
+		return nil, scaleKustomizeOverlay(addonDestPath, templateWriter)
+	case *backends.Manifests:
+		// applying the manifests would otherwise reset the replicas
+		return nil, unsetDeploymentReplicas(path.Join(addonDestPath, "deployment.yaml"), templateWriter)
+	}
+	return nil, fmt.Errorf("deployment type %s doesn't support autoscaling addons", backend.DeployType())
+}
+
+// scaleHelmChart enables autoscaling in the chart's values.yaml, which the deployment template of the charts draft
+// generates leaves the replicas to, and keeps the values there, so the autoscaler's template reads them from .Values.
+// values.yaml is edited in place, keeping its comments and layout.
+func scaleHelmChart(chartPath string, values map[string]string, templateWriter templatewriter.TemplateWriter) (map[string]string, error) {
+	valuesPath := path.Join(chartPath, chartutil.ValuesfileName)
+	content, err := os.ReadFile(valuesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{helmAutoscalingEnabled: "true"}
+	expressions := make(map[string]string, len(values))
+	for valuePath, value := range values {
+		settings[valuePath] = value
+		expressions[valuePath] = "{{ .Values." + valuePath + " }}"
+	}
+	valuePaths := maps.Keys(settings)
+	sort.Strings(valuePaths)
+	lines := strings.Split(string(content), "\n")
+	for _, valuePath := range valuePaths {
+		if lines, err = setValue(lines, strings.Split(valuePath, "."), settings[valuePath]); err != nil {
+			return nil, fmt.Errorf("setting %s in %s: %w", valuePath, valuesPath, err)
+		}
+	}
+
+	edited := strings.Join(lines, "\n")
+	if _, err = kyaml.Parse(edited); err != nil {
+		return nil, fmt.Errorf("editing %s: %w", valuesPath, err)
+	}
+	return expressions, templateWriter.WriteFile(valuesPath, []byte(edited))
+}
+
+// setValue sets the field of a yaml mapping at the path of fields in lines to value. An existing field is replaced,
+// a commented out one is uncommented, and a missing one is added after the last field of its mapping.
+func setValue(lines []string, fields []string, value string) ([]string, error) {
+	// the lines from start to end hold the mapping of the field, whose keys are indented by indent
+	start, end, indent := 0, len(lines), 0
+	for i, field := range fields {
+		key, commented := -1, -1
+		for j := start; j < end && key == -1; j++ {
+			if lineIndent(lines[j]) != indent {
+				continue
+			}
+			trimmed := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(trimmed, field+":") {
+				key = j
+			} else if commented == -1 && strings.HasPrefix(trimmed, "# "+field+":") {
+				commented = j
+			}
+		}
+
+		prefix := strings.Repeat(" ", indent)
+		if i == len(fields)-1 {
+			setting := prefix + field + ": " + value
+			switch {
+			case key != -1:
+				lines[key] = setting
+			case commented != -1:
+				lines[commented] = setting
+			default:
+				lines = slices.Insert(lines, lastField(lines, start, end)+1, setting)
+			}
+			return lines, nil
+		}
+
+		if key == -1 {
+			key = lastField(lines, start, end) + 1
+			lines = slices.Insert(lines, key, prefix+field+":")
+			end++
+		} else if inline := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[key]), field+":")); inline != "" && !strings.HasPrefix(inline, "#") {
+			return nil, fmt.Errorf("%s isn't a mapping", strings.Join(fields[:i+1], "."))
+		}
+
+		// the field's mapping is the lines below it that are indented further
+		childEnd := key + 1
+		for childEnd < end && (strings.TrimSpace(lines[childEnd]) == "" || lineIndent(lines[childEnd]) > indent) {
+			childEnd++
+		}
+		start, end = key+1, childEnd
+		indent = mappingIndent(lines[start:end], indent)
+	}
+	return lines, nil
+}
+
+// lastField returns the last line from start to end that isn't blank or a comment, or the line before start if
+// there's none
+func lastField(lines []string, start, end int) int {
+	for j := end - 1; j >= start; j-- {
+		if trimmed := strings.TrimSpace(lines[j]); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return j
+		}
+	}
+	return start - 1
+}
+
+// mappingIndent returns the indentation of the keys of the mapping in lines, nested in one indented by parentIndent
+func mappingIndent(lines []string, parentIndent int) int {
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return lineIndent(line)
+		}
+	}
+	return parentIndent + 2
+}
+
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// scaleKustomizeOverlay removes the replicas of the overlay's deployment patch, and patches the overlay to remove those
+// of the Deployments its resources set the replicas of
+func scaleKustomizeOverlay(overlayDir string, templateWriter templatewriter.TemplateWriter) error {
+	patchPath := path.Join(overlayDir, "deployment.yaml")
+	if _, err := os.Stat(patchPath); err == nil {
+		if err = unsetDeploymentReplicas(patchPath, templateWriter); err != nil {
+			return err
+		}
+	}
+
+	kustomizationPath, err := backends.KustomizationPath(overlayDir)
+	if err != nil {
+		return err
+	}
+	kustomization, err := kyaml.ReadFile(kustomizationPath)
+	if err != nil {
+		return err
+	}
+	resources, err := kustomization.Pipe(kyaml.Lookup("resources"))
+	if err != nil {
+		return err
+	}
+	patches, err := kustomization.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "patches"))
+	if err != nil {
+		return err
+	}
+
+	patched := false
+	for _, resource := range resources.Content() {
+		deployments, err := scaledDeployments(path.Join(overlayDir, resource.Value))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", resource.Value, err)
+		}
+		for _, deployment := range deployments {
+			patch, err := kyaml.Parse(fmt.Sprintf(replicasPatch, deployment))
+			if err != nil {
+				return err
+			}
+			if hasPatch(patches, patch) {
+				continue
+			}
+			if err = patches.PipeE(kyaml.Append(patch.YNode())); err != nil {
+				return err
+			}
+			patched = true
+		}
+	}
+	if !patched {
+		return nil
+	}
+
+	content, err := kustomization.String()
+	if err != nil {
+		return err
+	}
+	return templateWriter.WriteFile(kustomizationPath, []byte(content))
+}
+
+// replicasPatch is the patch of the Deployment it's formatted with removing its replicas
+const replicasPatch = `target:
+  kind: Deployment
+  name: %s
+patch: |-
+  - op: remove
+    path: /spec/replicas
+`
+
+// scaledDeployments returns the names of the Deployments setting their replicas in the kustomization or manifest
+// at resourcePath
+func scaledDeployments(resourcePath string) ([]string, error) {
+	var resources []*kyaml.RNode
+	if info, err := os.Stat(resourcePath); err == nil && info.IsDir() {
+		kustomizer := krusty.MakeKustomizer(&krusty.Options{PluginConfig: &types.PluginConfig{}})
+		resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), resourcePath)
+		if err != nil {
+			return nil, err
+		}
+		resources = resMap.ToRNodeSlice()
+	} else {
+		content, err := os.ReadFile(resourcePath)
+		if err != nil {
+			return nil, err
+		}
+		if resources, err = kio.FromBytes(content); err != nil {
+			return nil, err
+		}
+	}
+
+	var names []string
+	for _, resource := range resources {
+		if resource.GetKind() == "Deployment" && resource.Field("spec") != nil && resource.Field("spec").Value.Field("replicas") != nil {
+			names = append(names, resource.GetName())
+		}
+	}
+	return names, nil
+}
+
+// hasPatch reports whether patches already hold patch
+func hasPatch(patches *kyaml.RNode, patch *kyaml.RNode) bool {
+	patchContent, err := patch.String()
+	if err != nil {
+		return false
+	}
+	for _, existing := range patches.Content() {
+		if content, err := kyaml.NewRNode(existing).String(); err == nil && content == patchContent {
+			return true
+		}
+	}
+	return false
+}
+
+// unsetDeploymentReplicas removes the replicas of the Deployment in filePath
+func unsetDeploymentReplicas(filePath string, templateWriter templatewriter.TemplateWriter) error {
+	deployment, err := kyaml.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	replicas, err := deployment.Pipe(kyaml.Lookup("spec"), kyaml.Clear("replicas"))
+	if err != nil || replicas == nil {
+		return err
+	}
+
+	content, err := deployment.String()
+	if err != nil {
+		return err
+	}
+	return templateWriter.WriteFile(filePath, []byte(content))
+}
@@ -0,0 +1,147 @@
+package addons
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/backends"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func copyTestFile(t *testing.T, src, dest string) {
+	content, err := os.ReadFile(src)
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	assert.Nil(t, os.WriteFile(dest, content, 0644))
+}
+
+func TestScaleHelmChart(t *testing.T) {
+	dest := t.TempDir()
+	copyTestFile(t, filepath.Join(templatePath, "helm", "charts", "Chart.yaml"), filepath.Join(dest, "charts", "Chart.yaml"))
+	copyTestFile(t, filepath.Join(templatePath, "helm", "charts", "values.yaml"), filepath.Join(dest, "charts", "values.yaml"))
+
+	expressions, err := scaleDeployment(&backends.Helm{}, dest, backends.Target{}, map[string]string{
+		"autoscaling.minReplicas":                       "3",
+		"autoscaling.maxReplicas":                       "10",
+		"autoscaling.targetCPUUtilizationPercentage":    "60",
+		"autoscaling.targetMemoryUtilizationPercentage": "70",
+	}, &writers.LocalFSWriter{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"autoscaling.minReplicas":                       "{{ .Values.autoscaling.minReplicas }}",
+		"autoscaling.maxReplicas":                       "{{ .Values.autoscaling.maxReplicas }}",
+		"autoscaling.targetCPUUtilizationPercentage":    "{{ .Values.autoscaling.targetCPUUtilizationPercentage }}",
+		"autoscaling.targetMemoryUtilizationPercentage": "{{ .Values.autoscaling.targetMemoryUtilizationPercentage }}",
+	}, expressions)
+
+	// values.yaml keeps its comments and layout, only changing the autoscaling values
+	values, err := os.ReadFile(filepath.Join(dest, "charts", "values.yaml"))
+	assert.Nil(t, err)
+	golden, err := os.ReadFile("testdata/golden/values.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, string(golden), string(values))
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "replaces a field",
+			values: "autoscaling:\n  enabled: false # off\n\nimage: test\n",
+			path:   "autoscaling.enabled",
+			want:   "autoscaling:\n  enabled: true\n\nimage: test\n",
+		},
+		{
+			name:   "uncomments a field",
+			values: "autoscaling:\n    minReplicas: 1\n    # enabled: false\nimage: test\n",
+			path:   "autoscaling.enabled",
+			want:   "autoscaling:\n    minReplicas: 1\n    enabled: true\nimage: test\n",
+		},
+		{
+			name:   "adds a field after the last of its mapping",
+			values: "autoscaling:\n  minReplicas: 1\n  # a comment\n\nimage: test\n",
+			path:   "autoscaling.enabled",
+			want:   "autoscaling:\n  minReplicas: 1\n  enabled: true\n  # a comment\n\nimage: test\n",
+		},
+		{
+			name:   "adds a missing mapping",
+			values: "image: test\n",
+			path:   "autoscaling.enabled",
+			want:   "image: test\nautoscaling:\n  enabled: true\n",
+		},
+		{
+			name:    "fails on a flow mapping",
+			values:  "autoscaling: {}\n",
+			path:    "autoscaling.enabled",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := setValue(strings.Split(tt.values, "\n"), strings.Split(tt.path, "."), "true")
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, strings.Join(lines, "\n"))
+		})
+	}
+}
+
+func TestScaleKustomizeOverlay(t *testing.T) {
+	dest := t.TempDir()
+	for _, file := range []string{"base/kustomization.yaml", "base/deployment.yaml", "base/service.yaml", "overlays/production/kustomization.yaml", "overlays/production/deployment.yaml", "overlays/production/service.yaml"} {
+		copyTestFile(t, filepath.Join(templatePath, "kustomize", file), filepath.Join(dest, file))
+	}
+	overlay := filepath.Join(dest, "overlays", "production")
+	// the patch sets replicas too, which it stops doing
+	patch, err := os.ReadFile(filepath.Join(overlay, "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(overlay, "deployment.yaml"), []byte(strings.Replace(string(patch), "spec:\n", "spec:\n  replicas: 3\n", 1)), 0644))
+
+	templateWriter := &writers.LocalFSWriter{}
+	for i := 0; i < 2; i++ {
+		expressions, err := scaleDeployment(&backends.Kustomize{}, dest, backends.Target{}, nil, templateWriter)
+		assert.Nil(t, err)
+		assert.Empty(t, expressions)
+	}
+
+	patch, err = os.ReadFile(filepath.Join(overlay, "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(patch), "replicas")
+	kustomization, err := os.ReadFile(filepath.Join(overlay, "kustomization.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(kustomization), "path: /spec/replicas"), "the base's replicas are removed once")
+	assert.Contains(t, string(kustomization), "    name: test\n")
+
+	files, err := readDir(dest)
+	assert.Nil(t, err)
+	rendered, err := (&backends.Kustomize{}).Render(files)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rendered["overlays/production"]), "name: production-test")
+	assert.NotContains(t, string(rendered["overlays/production"]), "replicas")
+	assert.Contains(t, string(rendered["base"]), "replicas: 1")
+}
+
+func TestScaleManifests(t *testing.T) {
+	dest := t.TempDir()
+	copyTestFile(t, filepath.Join(templatePath, "deployment.yaml"), filepath.Join(dest, "manifests", "deployment.yaml"))
+
+	expressions, err := scaleDeployment(&backends.Manifests{}, dest, backends.Target{}, map[string]string{"autoscaling.minReplicas": "3"}, &writers.LocalFSWriter{})
+	assert.Nil(t, err)
+	assert.Empty(t, expressions)
+	manifest, err := os.ReadFile(filepath.Join(dest, "manifests", "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(manifest), "replicas")
+	assert.Contains(t, string(manifest), "containerPort: 8000")
+}
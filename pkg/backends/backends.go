@@ -0,0 +1,93 @@
+// Package backends implements the deploy types draft generates deployment files for, such as helm charts, behind
+// the DeploymentBackend interface. Supporting a new deploy type means implementing DeploymentBackend and adding it
+// to deploymentBackends.
+package backends
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// DeploymentBackend is a deploy type and the deployment files draft generates for it
+type DeploymentBackend interface {
+	// DeployType is the name of the deploy type and of its template packs, such as helm
+	DeployType() string
+	// Detect reports whether the project in dest has deployment files of this deploy type
+	Detect(dest string) (bool, error)
+	// Dirs returns the directories, relative to dest, that may hold deployment files of this deploy type
+	Dirs(dest string) ([]string, error)
+	// Render returns the Kubernetes resources of the deployment files, keyed by the file, chart or kustomization
+	// they come from. files maps the paths of the project's files, relative to the project directory, to their content.
+	Render(files map[string][]byte) (map[string][]byte, error)
+	// SetImage sets the container image the target deploys to production
+	SetImage(dest string, target Target, image string, templateWriter templatewriter.TemplateWriter) error
+	// ReferenceValues returns the values of the references to the target's resource of the given kind, such as
+	// service, or kind/name when there are several, keyed by reference name
+	ReferenceValues(dest string, target Target, resource string, references []Reference) (map[string]string, error)
+	// AddonDestPath returns the directory addons for the target are written to
+	AddonDestPath(dest string, target Target) (string, error)
+	// AddAddonFiles adds the files of an addon, relative to AddonDestPath, to the resources the target deploys
+	AddAddonFiles(dest string, target Target, files []string, templateWriter templatewriter.TemplateWriter) error
+}
+
+// Target selects the deployment files a backend works on when a project has several, using paths relative to the
+// project directory. Empty fields use the backend's defaults.
+type Target struct {
+	// HelmChart is the chart directory, defaulting to the only chart in the project
+	HelmChart string
+	// HelmValuesFile is the production values file, defaulting to production.yaml in the chart
+	HelmValuesFile string
+	// KustomizeOverlay is the overlay directory, defaulting to overlays/production
+	KustomizeOverlay string
+}
+
+// Reference is a variable of an addon read from a resource of the project's deployment files
+type Reference struct {
+	Name string `yaml:"name"`
+	// Path is the field of the resource the variable is read from, such as metadata.name or
+	// spec.template.spec.containers[name=app].ports[0].containerPort
+	Path string `yaml:"path"`
+}
+
+// deploymentBackends are the supported deploy types, in the order they're detected in
+var deploymentBackends = []DeploymentBackend{&Helm{}, &Kustomize{}, &Manifests{}}
+
+// All returns the supported deploy types, in the order they're detected in
+func All() []DeploymentBackend {
+	return deploymentBackends
+}
+
+// DeployTypes returns the names of the supported deploy types
+func DeployTypes() []string {
+	deployTypes := make([]string, 0, len(deploymentBackends))
+	for _, backend := range deploymentBackends {
+		deployTypes = append(deployTypes, backend.DeployType())
+	}
+	return deployTypes
+}
+
+// Get returns the backend of deployType
+func Get(deployType string) (DeploymentBackend, error) {
+	for _, backend := range deploymentBackends {
+		if backend.DeployType() == deployType {
+			return backend, nil
+		}
+	}
+	return nil, fmt.Errorf("deployment type %s is not supported", deployType)
+}
+
+// Detect returns the backend of the first deploy type whose deployment files are found in dest
+func Detect(dest string) (DeploymentBackend, error) {
+	for _, backend := range deploymentBackends {
+		found, err := backend.Detect(dest)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return backend, nil
+		}
+	}
+	return nil, errors.New("no supported deployment files found")
+}
@@ -0,0 +1,299 @@
+package backends
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestGet(t *testing.T) {
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, DeployTypes())
+	for _, deployType := range DeployTypes() {
+		backend, err := Get(deployType)
+		assert.Nil(t, err)
+		assert.Equal(t, deployType, backend.DeployType())
+	}
+
+	_, err := Get("unsupported")
+	assert.NotNil(t, err)
+}
+
+func TestDetect(t *testing.T) {
+	for _, deployType := range DeployTypes() {
+		backend, err := Detect("../../test/templates/" + deployType)
+		assert.Nil(t, err)
+		assert.Equal(t, deployType, backend.DeployType())
+	}
+
+	dir := t.TempDir()
+	_, err := Detect(dir)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "deploy"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "deploy", "Chart.yaml"), []byte("name: test"), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "manifests"), 0755))
+	backend, err := Detect(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "helm", backend.DeployType())
+
+	dirs, err := backend.Dirs(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"deploy"}, dirs)
+}
+
+func TestReferenceValues(t *testing.T) {
+	references := []Reference{
+		{Name: "service-name", Path: "metadata.name"},
+		{Name: "service-port", Path: "spec.ports.port"},
+		{Name: "service-namespace", Path: "metadata.namespace"},
+	}
+
+	for _, deployType := range DeployTypes() {
+		t.Run(deployType, func(t *testing.T) {
+			backend, err := Get(deployType)
+			assert.Nil(t, err)
+			values, err := backend.ReferenceValues("../../test/templates/"+deployType, Target{}, "service", references)
+			assert.Nil(t, err)
+			assert.Len(t, values, 3)
+			for _, reference := range references {
+				assert.NotEmpty(t, values[reference.Name])
+			}
+		})
+	}
+
+	_, err := (&Manifests{}).ReferenceValues("../../test/templates/manifests", Target{}, "service", []Reference{{Name: "service-missing", Path: "metadata.missing"}})
+	assert.NotNil(t, err)
+}
+
+func TestDeploymentReferenceValues(t *testing.T) {
+	references := []Reference{
+		{Name: "deployment-name", Path: "metadata.name"},
+		{Name: "deployment-namespace", Path: "metadata.namespace"},
+		{Name: "container-port", Path: "spec.template.spec.containers.ports[0].containerPort"},
+	}
+	tests := []struct {
+		deployType string
+		expected   map[string]string
+	}{
+		{
+			deployType: "helm",
+			expected: map[string]string{
+				"deployment-name":      `{{ include "test.fullname" . }}`,
+				"deployment-namespace": "{{ .Release.Namespace }}",
+				"container-port":       "{{ .Values.containerPort }}",
+			},
+		},
+		{
+			deployType: "kustomize",
+			expected: map[string]string{
+				"deployment-name":      "test",
+				"deployment-namespace": "default",
+				"container-port":       "80",
+			},
+		},
+		{
+			deployType: "manifests",
+			expected: map[string]string{
+				"deployment-name":      "test",
+				"deployment-namespace": "default",
+				"container-port":       "8000",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.deployType, func(t *testing.T) {
+			backend, err := Get(test.deployType)
+			assert.Nil(t, err)
+			values, err := backend.ReferenceValues("../../test/templates/"+test.deployType, Target{}, "Deployment", references)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, values)
+
+			_, err = backend.ReferenceValues("../../test/templates/"+test.deployType, Target{}, "serviceaccount", references)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestAddonDestPath(t *testing.T) {
+	tests := []struct {
+		backend  DeploymentBackend
+		target   Target
+		expected string
+	}{
+		{&Helm{}, Target{}, "../../test/templates/helm/charts/templates"},
+		{&Helm{}, Target{HelmChart: "charts/web"}, "../../test/templates/helm/charts/web/templates"},
+		{&Kustomize{}, Target{}, "../../test/templates/helm/overlays/production"},
+		{&Kustomize{}, Target{KustomizeOverlay: "overlays/dev"}, "../../test/templates/helm/overlays/dev"},
+		{&Manifests{}, Target{}, "../../test/templates/helm/manifests"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			destPath, err := test.backend.AddonDestPath("../../test/templates/helm", test.target)
+			assert.Nil(t, err)
+			assert.Equal(t, filepath.Clean(test.expected), destPath)
+		})
+	}
+}
+
+func TestSetImage(t *testing.T) {
+	dest := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "charts"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "charts", "Chart.yaml"), []byte("name: test"), 0644))
+	copyFile(t, "../../test/templates/helm_prod_values.yaml", filepath.Join(dest, "charts", "production.yaml"))
+	copyFile(t, "../../test/templates/deployment.yaml", filepath.Join(dest, "manifests", "deployment.yaml"))
+	copyFile(t, "../../test/templates/deployment.yaml", filepath.Join(dest, "overlays", "dev", "deployment.yaml"))
+
+	testTemplateWriter := &writers.LocalFSWriter{}
+	assert.Nil(t, (&Helm{}).SetImage(dest, Target{}, "helmImage", testTemplateWriter))
+	helmDeploy := &HelmProductionYaml{}
+	assert.Nil(t, helmDeploy.LoadFromFile(filepath.Join(dest, "charts", "production.yaml")))
+	assert.Equal(t, "helmImage", helmDeploy.Image.Repository)
+
+	assert.Nil(t, (&Manifests{}).SetImage(dest, Target{}, "manifestsImage", testTemplateWriter))
+	assert.Equal(t, "manifestsImage", deploymentImage(t, filepath.Join(dest, "manifests", "deployment.yaml")))
+
+	assert.NotNil(t, (&Kustomize{}).SetImage(dest, Target{}, "kustomizeImage", testTemplateWriter))
+	assert.Nil(t, (&Kustomize{}).SetImage(dest, Target{KustomizeOverlay: "./overlays/dev"}, "kustomizeImage", testTemplateWriter))
+	assert.Equal(t, "kustomizeImage", deploymentImage(t, filepath.Join(dest, "overlays", "dev", "deployment.yaml")))
+}
+
+func TestSetContainerImageValid(t *testing.T) {
+	testTemplateWriter := &writers.LocalFSWriter{}
+
+	//test for valid helm deployment file
+	helmFileName, _ := createTempManifest("../../test/templates/helm_prod_values.yaml")
+	defer os.Remove(helmFileName)
+
+	assert.Nil(t, setHelmContainerImage(helmFileName, "testImage", testTemplateWriter))
+
+	helmDeploy := &HelmProductionYaml{}
+	assert.Nil(t, helmDeploy.LoadFromFile(helmFileName))
+	assert.Equal(t, "testImage", helmDeploy.Image.Repository)
+
+	//test for valid deployment file
+	deploymentFileName, _ := createTempManifest("../../test/templates/deployment.yaml")
+	defer os.Remove(deploymentFileName)
+
+	assert.Nil(t, setDeploymentContainerImage(deploymentFileName, "testImage", testTemplateWriter))
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	file, err := ioutil.ReadFile(deploymentFileName)
+	assert.Nil(t, err)
+
+	k8sObj, _, err := decode(file, nil, nil)
+	assert.Nil(t, err)
+
+	deploy, ok := k8sObj.(*appsv1.Deployment)
+	assert.True(t, ok)
+	assert.Equal(t, "testImage", deploy.Spec.Template.Spec.Containers[0].Image)
+
+	//test that a shorter deployment replaces the whole file
+	deployment, err := ioutil.ReadFile("../../test/templates/deployment.yaml")
+	assert.Nil(t, err)
+	padded := append(deployment, []byte("\n# "+strings.Repeat("padding ", 200)+"\n")...)
+	assert.Nil(t, ioutil.WriteFile(deploymentFileName, padded, 0644))
+	assert.Nil(t, setDeploymentContainerImage(deploymentFileName, "testImage", testTemplateWriter))
+	assert.Equal(t, "testImage", deploymentImage(t, deploymentFileName))
+	file, err = ioutil.ReadFile(deploymentFileName)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(file), "padding")
+}
+
+func TestSetContainerImageInvalid(t *testing.T) {
+	testTemplateWriter := &writers.LocalFSWriter{}
+
+	//test for invalid helm deployment file
+	tempFile, err := ioutil.TempFile("", "*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(tempFile.Name())
+	yamlData := []byte(`not a valid yaml`)
+	_, err = tempFile.Write(yamlData)
+	assert.Nil(t, err)
+	err = tempFile.Close()
+	assert.Nil(t, err)
+	assert.NotNil(t, setHelmContainerImage(tempFile.Name(), "testImage", testTemplateWriter))
+
+	//test for missing deployment file
+	assert.NotNil(t, setDeploymentContainerImage(filepath.Join(t.TempDir(), "deployment.yaml"), "testImage", testTemplateWriter))
+
+	//test for invalid deployment file
+	assert.NotNil(t, setDeploymentContainerImage(tempFile.Name(), "testImage", testTemplateWriter))
+
+	//test for invalid k8sObj
+	invalidDeploymentFile, _ := createTempManifest("../../test/templates/invalid_deployment.yaml")
+	assert.Equal(t, errors.New("could not decode kubernetes deployment"), setDeploymentContainerImage(invalidDeploymentFile, "testImage", testTemplateWriter))
+
+	//test for unsupported number of containers in the deployment spec
+	invalidDeploymentFile, _ = createTempManifest("../../test/templates/unsupported_no_of_containers.yaml")
+	defer os.Remove(invalidDeploymentFile)
+	assert.Equal(t, errors.New("unsupported number of containers defined in the deployment spec"), setDeploymentContainerImage(invalidDeploymentFile, "testImage", testTemplateWriter))
+}
+
+func createTempManifest(path string) (string, error) {
+	file, err := ioutil.TempFile("", "*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var source *os.File
+	source, err = os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	_, err = io.Copy(file, source)
+	if err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+func copyFile(t *testing.T, src, dest string) {
+	content, err := os.ReadFile(src)
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	assert.Nil(t, os.WriteFile(dest, content, 0644))
+}
+
+func deploymentImage(t *testing.T, filePath string) string {
+	file, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	k8sObj, _, err := scheme.Codecs.UniversalDeserializer().Decode(file, nil, nil)
+	assert.Nil(t, err)
+	deploy, ok := k8sObj.(*appsv1.Deployment)
+	assert.True(t, ok)
+	return deploy.Spec.Template.Spec.Containers[0].Image
+}
+
+func TestAddAddonFiles(t *testing.T) {
+	dest := t.TempDir()
+	overlay := filepath.Join(dest, "overlays", "production")
+	for _, name := range []string{"kustomization.yaml", "deployment.yaml", "service.yaml"} {
+		copyFile(t, filepath.Join("../../test/templates/kustomize/overlays/production", name), filepath.Join(overlay, name))
+	}
+
+	kustomize := &Kustomize{}
+	templateWriter := &writers.LocalFSWriter{}
+	assert.Nil(t, kustomize.AddAddonFiles(dest, Target{}, []string{"certificate.yaml", "ingress.yaml"}, templateWriter))
+	assert.Nil(t, kustomize.AddAddonFiles(dest, Target{}, []string{"certificate.yaml"}, templateWriter))
+	content, err := os.ReadFile(filepath.Join(overlay, "kustomization.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "resources:\n- ../../base\n- certificate.yaml\n- ingress.yaml\n")
+	assert.Contains(t, string(content), "namePrefix: production-")
+
+	assert.NotNil(t, kustomize.AddAddonFiles(dest, Target{KustomizeOverlay: "overlays/staging"}, []string{"certificate.yaml"}, templateWriter))
+	assert.Nil(t, (&Helm{}).AddAddonFiles(dest, Target{}, []string{"certificate.yaml"}, templateWriter))
+	assert.Nil(t, (&Manifests{}).AddAddonFiles(dest, Target{}, []string{"certificate.yaml"}, templateWriter))
+}
@@ -0,0 +1,175 @@
+package backends
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// Helm deploys with the helm charts in the project
+type Helm struct{}
+
+func (h *Helm) DeployType() string {
+	return "helm"
+}
+
+func (h *Helm) Detect(dest string) (bool, error) {
+	if charts, err := filematches.FindHelmCharts(dest); err == nil && len(charts) > 0 {
+		return true, nil
+	}
+	if _, err := os.Stat(dest + "/charts"); !os.IsNotExist(err) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (h *Helm) Dirs(dest string) ([]string, error) {
+	return filematches.FindHelmCharts(dest)
+}
+
+func (h *Helm) Render(files map[string][]byte) (map[string][]byte, error) {
+	return renderHelmCharts(files)
+}
+
+func (h *Helm) SetImage(dest string, target Target, image string, templateWriter templatewriter.TemplateWriter) error {
+	valuesPath, err := h.valuesPath(dest, target)
+	if err != nil {
+		return err
+	}
+	return setHelmContainerImage(valuesPath, image, templateWriter)
+}
+
+// ReferenceValues renders the chart with its values.yaml and production values file and reads the references from the
+// rendered resources. Addons are written into the chart, so values that come from the chart's values, release or
+// named templates are returned as the template expressions they're rendered from, such as {{ .Values.service.port }}.
+func (h *Helm) ReferenceValues(dest string, target Target, resource string, references []Reference) (map[string]string, error) {
+	chartPath, err := h.chartPath(dest, target)
+	if err != nil {
+		return nil, err
+	}
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	values, err := h.values(chrt, dest, target)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := renderChart(chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("rendering helm chart %s: %w", chartPath, err)
+	}
+	nodes, err := parseResources(map[string][]byte{chartPath: manifests})
+	if err != nil {
+		return nil, err
+	}
+	referenceValues, err := resolveReferences(nodes, resource, references, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	trace, traced := traceHelmReferences(chrt, values, resource, references)
+	if trace == nil {
+		return referenceValues, nil
+	}
+	for name, value := range referenceValues {
+		if tracedValue, ok := traced[name]; ok {
+			referenceValues[name] = trace.expression(tracedValue, value)
+		}
+	}
+	return referenceValues, nil
+}
+
+func (h *Helm) AddonDestPath(dest string, target Target) (string, error) {
+	chartPath, err := h.chartPath(dest, target)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(chartPath, "templates"), nil
+}
+
+// AddAddonFiles does nothing, since every template in the chart's templates directory is deployed
+func (h *Helm) AddAddonFiles(dest string, target Target, files []string, templateWriter templatewriter.TemplateWriter) error {
+	return nil
+}
+
+// valuesPath returns the path of the target's production values file
+func (h *Helm) valuesPath(dest string, target Target) (string, error) {
+	if target.HelmValuesFile != "" {
+		return path.Join(dest, target.HelmValuesFile), nil
+	}
+	chartPath, err := h.chartPath(dest, target)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(chartPath, "production.yaml"), nil
+}
+
+// values returns the chart's values overridden by the target's production values file, when there is one
+func (h *Helm) values(chrt *chart.Chart, dest string, target Target) (map[string]interface{}, error) {
+	valuesPath, err := h.valuesPath(dest, target)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]interface{})
+	if _, err := os.Stat(valuesPath); err == nil || target.HelmValuesFile != "" {
+		if overrides, err = chartutil.ReadValuesFile(valuesPath); err != nil {
+			return nil, err
+		}
+	}
+	values, err := chartutil.CoalesceValues(chrt, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// chartPath returns the path of the target's chart, which is the only chart in dest when none is set
+func (h *Helm) chartPath(dest string, target Target) (string, error) {
+	if target.HelmChart != "" {
+		return path.Join(dest, target.HelmChart), nil
+	}
+	charts, err := filematches.FindHelmCharts(dest)
+	if err != nil {
+		return "", err
+	}
+	switch len(charts) {
+	case 0:
+		return "", fmt.Errorf("no helm chart found in %s", dest)
+	case 1:
+		return path.Join(dest, charts[0]), nil
+	}
+	return "", fmt.Errorf("found multiple helm charts in %s, choose one of %v", dest, charts)
+}
+
+func setHelmContainerImage(filePath, productionImage string, templateWriter templatewriter.TemplateWriter) error {
+	file, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var deploy HelmProductionYaml
+	err = yaml.Unmarshal(file, &deploy)
+	if err != nil {
+		return err
+	}
+
+	deploy.Image.Repository = productionImage
+
+	out, err := yaml.Marshal(deploy)
+	if err != nil {
+		return err
+	}
+
+	return templateWriter.WriteFile(filePath, out)
+}
@@ -0,0 +1,200 @@
+package backends
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// the markers .Release.Name and .Release.Namespace are traced with
+const (
+	traceReleaseName = "draftrelease"
+	traceNamespace   = "draftnamespace"
+)
+
+// traceIncludeTemplate is the template the named templates of a chart are rendered in while tracing
+const traceIncludeTemplate = "draft-trace-include"
+
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	definePattern     = regexp.MustCompile(`{{-?\s*define\s+"([^"]+)"`)
+)
+
+// helmExpression is a template expression of a chart and the value it renders to
+type helmExpression struct {
+	expression string
+	value      string
+}
+
+// helmTrace maps values rendered by a chart back to the template expressions they come from. The chart is rendered a
+// second time with markers in place of its values and release, so the markers found in a rendered field tell which
+// expressions the field is made of.
+type helmTrace struct {
+	// markers are the expressions of the markers
+	markers map[string]helmExpression
+	// includes are the named templates of the chart, such as <chart>.fullname, by their traced output
+	includes map[string]helmExpression
+}
+
+func newHelmTrace() *helmTrace {
+	return &helmTrace{
+		markers: map[string]helmExpression{
+			traceReleaseName: {expression: "{{ .Release.Name }}", value: renderReleaseName},
+			traceNamespace:   {expression: "{{ .Release.Namespace }}", value: defaultNamespace},
+		},
+		includes: make(map[string]helmExpression),
+	}
+}
+
+// traceHelmReferences returns the traced values of the references to resource in the chart rendered with values, by
+// reference name. Failing to trace isn't an error, since references then keep the values the chart renders to.
+func traceHelmReferences(chrt *chart.Chart, values map[string]interface{}, resource string, references []Reference) (*helmTrace, map[string]string) {
+	trace := newHelmTrace()
+	renderValues := chartutil.Values{
+		"Chart":        chrt.Metadata,
+		"Capabilities": chartutil.DefaultCapabilities,
+		"Release": map[string]interface{}{
+			"Name":      traceReleaseName,
+			"Namespace": traceNamespace,
+			"IsUpgrade": false,
+			"IsInstall": true,
+			"Revision":  1,
+			"Service":   "Helm",
+		},
+		// the markers aren't validated against values.schema.json, which they may not match
+		"Values": trace.markValues(values, ".Values"),
+	}
+
+	templates, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		log.Debugf("could not trace the values of chart %s: %v", chrt.Name(), err)
+		return nil, nil
+	}
+	nodes, err := parseResources(map[string][]byte{chrt.Name(): joinManifests(templates)})
+	if err != nil {
+		log.Debugf("could not trace the values of chart %s: %v", chrt.Name(), err)
+		return nil, nil
+	}
+	traced := make(map[string]string)
+	for _, reference := range references {
+		// the traced render may take other branches of the templates, leaving out some references
+		value, err := resolveReferences(nodes, resource, []Reference{reference}, traceNamespace)
+		if err != nil {
+			log.Debugf("could not trace reference %s of chart %s: %v", reference.Name, chrt.Name(), err)
+			continue
+		}
+		traced[reference.Name] = value[reference.Name]
+	}
+
+	trace.traceIncludes(chrt, renderValues)
+	return trace, traced
+}
+
+// markValues returns a copy of values with a marker in place of every non-empty string and non-zero number, so the
+// conditions of the chart's templates render the same
+func (t *helmTrace) markValues(values map[string]interface{}, expression string) map[string]interface{} {
+	marked := make(map[string]interface{}, len(values))
+	for _, key := range sortedKeys(values) {
+		value := values[key]
+		marked[key] = value
+		if !identifierPattern.MatchString(key) {
+			continue
+		}
+
+		keyExpression := expression + "." + key
+		switch v := value.(type) {
+		case map[string]interface{}:
+			marked[key] = t.markValues(v, keyExpression)
+		case chartutil.Values:
+			marked[key] = t.markValues(v, keyExpression)
+		case string:
+			if v != "" {
+				marked[key] = t.mark(keyExpression, v)
+			}
+		case int, int64, float64:
+			if s := fmt.Sprint(v); s != "0" {
+				marked[key] = t.mark(keyExpression, s)
+			}
+		}
+	}
+	return marked
+}
+
+func (t *helmTrace) mark(expression, value string) string {
+	// markers end with a letter so none is the prefix of another
+	marker := fmt.Sprintf("draft%dtrace", len(t.markers))
+	t.markers[marker] = helmExpression{expression: "{{ " + expression + " }}", value: value}
+	return marker
+}
+
+// traceIncludes renders the named templates of the chart, keeping those made of traced values and rendering to a
+// single line
+func (t *helmTrace) traceIncludes(chrt *chart.Chart, renderValues chartutil.Values) {
+	var names []string
+	for _, template := range chrt.Templates {
+		for _, match := range definePattern.FindAllStringSubmatch(string(template.Data), -1) {
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// only the partials are needed to render a named template
+		includeChart := *chrt
+		includeChart.Templates = []*chart.File{{
+			Name: "templates/" + traceIncludeTemplate,
+			Data: []byte(fmt.Sprintf("{{ include %q . }}", name)),
+		}}
+		for _, template := range chrt.Templates {
+			if strings.HasPrefix(template.Name, "templates/_") {
+				includeChart.Templates = append(includeChart.Templates, template)
+			}
+		}
+
+		rendered, err := engine.Render(&includeChart, renderValues)
+		if err != nil {
+			log.Debugf("could not render template %s of chart %s: %v", name, chrt.Name(), err)
+			continue
+		}
+		output := rendered[chrt.Name()+"/templates/"+traceIncludeTemplate]
+		if output == "" || strings.Contains(output, "\n") || t.substitute(output) == output {
+			continue
+		}
+		if _, ok := t.includes[output]; !ok {
+			t.includes[output] = helmExpression{expression: fmt.Sprintf("{{ include %q . }}", name), value: t.substitute(output)}
+		}
+	}
+}
+
+// substitute replaces the markers in traced with the values they stand for
+func (t *helmTrace) substitute(traced string) string {
+	var oldNew []string
+	for marker, expression := range t.markers {
+		oldNew = append(oldNew, marker, expression.value)
+	}
+	return strings.NewReplacer(oldNew...).Replace(traced)
+}
+
+// expression returns the template expression rendering to value, whose traced value is traced. Values that don't
+// come from the chart's values, release or named templates are returned as they are.
+func (t *helmTrace) expression(traced, value string) string {
+	if include, ok := t.includes[traced]; ok && include.value == value {
+		return include.expression
+	}
+	// the traced render took another branch of the templates
+	if t.substitute(traced) != value {
+		return value
+	}
+
+	var oldNew []string
+	for marker, expression := range t.markers {
+		oldNew = append(oldNew, marker, expression.expression)
+	}
+	return strings.NewReplacer(oldNew...).Replace(traced)
+}
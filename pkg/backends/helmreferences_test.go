@@ -0,0 +1,98 @@
+package backends
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// copyChart copies the test chart to a project in a temporary directory, with production.yaml replaced by production
+func copyChart(t *testing.T, production string) string {
+	dest := t.TempDir()
+	files, err := readFiles("../../test/templates/helm")
+	assert.Nil(t, err)
+	for name, content := range files {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dest, filepath.Dir(name)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dest, name), content, 0644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "charts", "production.yaml"), []byte(production), 0644))
+	return dest
+}
+
+func TestHelmReferenceValues(t *testing.T) {
+	dest := copyChart(t, "service:\n  port: 8443\n  type: ClusterIP\nfullnameOverride: my-app\n")
+	chartDir := filepath.Join(dest, "charts")
+	templates := filepath.Join(chartDir, "templates")
+	service, err := os.ReadFile(filepath.Join(templates, "service.yaml"))
+	assert.Nil(t, err)
+	service = append(service, []byte(`
+  {{- if eq .Values.service.type "ClusterIP" }}
+  sessionAffinity: ClientIP
+  {{- end }}
+`)...)
+	assert.Nil(t, os.WriteFile(filepath.Join(templates, "service.yaml"), service, 0644))
+
+	references := []Reference{
+		{Name: "service-name", Path: "metadata.name"},
+		{Name: "service-port", Path: "spec.ports[name=svchttp].port"},
+		{Name: "service-protocol", Path: "spec.ports.protocol"},
+		{Name: "service-namespace", Path: "metadata.namespace"},
+		{Name: "service-selector", Path: "spec.selector"},
+		{Name: "session-affinity", Path: "spec.sessionAffinity"},
+	}
+	values, err := (&Helm{}).ReferenceValues(dest, Target{}, "service", references)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"service-name":      `{{ include "test.fullname" . }}`,
+		"service-port":      "{{ .Values.service.port }}",
+		"service-protocol":  "TCP",
+		"service-namespace": "{{ .Release.Namespace }}",
+		"service-selector":  "{app.kubernetes.io/name: test, app.kubernetes.io/instance: {{ .Release.Name }}}",
+		// the traced render doesn't take the ClusterIP branch, so the rendered value is kept
+		"session-affinity": "ClientIP",
+	}, values)
+
+	values, err = (&Helm{}).ReferenceValues(dest, Target{}, "deployment", []Reference{{Name: "image", Path: "spec.template.spec.containers[0].image"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"image": "{{ .Values.image.repository }}:{{ .Values.image.tag }}"}, values)
+
+	// a missing production values file only matters when it's chosen explicitly
+	assert.Nil(t, os.Remove(filepath.Join(chartDir, "production.yaml")))
+	_, err = (&Helm{}).ReferenceValues(dest, Target{}, "service", references[:1])
+	assert.Nil(t, err)
+	_, err = (&Helm{}).ReferenceValues(dest, Target{HelmValuesFile: "charts/production.yaml"}, "service", references[:1])
+	assert.NotNil(t, err)
+}
+
+func TestHelmValues(t *testing.T) {
+	dest := copyChart(t, "containerPort: 9000\n")
+	h := &Helm{}
+	chrt, err := loader.Load(filepath.Join(dest, "charts"))
+	assert.Nil(t, err)
+	values, err := h.values(chrt, dest, Target{})
+	assert.Nil(t, err)
+
+	manifests, err := renderChart(chrt, values)
+	assert.Nil(t, err)
+	nodes, err := parseResources(map[string][]byte{"charts": manifests})
+	assert.Nil(t, err)
+	references := []Reference{
+		{Name: "container-port", Path: "spec.template.spec.containers.ports[name=http].containerPort"},
+		{Name: "replicas", Path: "spec.replicas"},
+	}
+	rendered, err := resolveReferences(nodes, "deployment", references, defaultNamespace)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"container-port": "9000", "replicas": "1"}, rendered)
+
+	trace, traced := traceHelmReferences(chrt, values, "deployment", references)
+	assert.NotNil(t, trace)
+	for _, reference := range references {
+		assert.Equal(t, rendered[reference.Name], trace.substitute(traced[reference.Name]))
+	}
+	assert.Equal(t, "{{ .Values.containerPort }}", trace.expression(traced["container-port"], "9000"))
+	assert.Equal(t, "{{ .Values.replicaCount }}", trace.expression(traced["replicas"], "1"))
+	assert.Equal(t, "2", trace.expression(traced["replicas"], "2"))
+}
@@ -0,0 +1,137 @@
+package backends
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/consts"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// Kustomize deploys with the kustomize base and overlays in the project
+type Kustomize struct{}
+
+func (k *Kustomize) DeployType() string {
+	return "kustomize"
+}
+
+func (k *Kustomize) Detect(dest string) (bool, error) {
+	_, err := os.Stat(path.Join(dest, consts.KustomizeOverlaysDir))
+	return !os.IsNotExist(err), nil
+}
+
+func (k *Kustomize) Dirs(dest string) ([]string, error) {
+	return []string{"base", consts.KustomizeOverlaysDir}, nil
+}
+
+func (k *Kustomize) Render(files map[string][]byte) (map[string][]byte, error) {
+	return buildKustomizations(files)
+}
+
+func (k *Kustomize) SetImage(dest string, target Target, image string, templateWriter templatewriter.TemplateWriter) error {
+	return setDeploymentContainerImage(path.Join(dest, k.overlayPath(target), "deployment.yaml"), image, templateWriter)
+}
+
+func (k *Kustomize) ReferenceValues(dest string, target Target, resource string, references []Reference) (map[string]string, error) {
+	overlayDir, _, err := filesys.MakeFsOnDisk().CleanedAbs(path.Join(dest, k.overlayPath(target)))
+	if err != nil {
+		return nil, err
+	}
+	// the overlay adds its name prefix and suffix to the addon's resources too, so names are read without them
+	fSys := unprefixedOverlayFS{FileSystem: filesys.MakeFsOnDisk(), overlayDir: overlayDir.String()}
+	kustomizer := krusty.MakeKustomizer(&krusty.Options{PluginConfig: &types.PluginConfig{}})
+	overlay, err := kustomizer.Run(fSys, overlayDir.String())
+	if err != nil {
+		return nil, err
+	}
+	return resolveReferences(overlay.ToRNodeSlice(), resource, references, defaultNamespace)
+}
+
+func (k *Kustomize) AddonDestPath(dest string, target Target) (string, error) {
+	return path.Join(dest, k.overlayPath(target)), nil
+}
+
+// AddAddonFiles adds the files to the resources of the overlay's kustomization, which only builds the files it lists
+func (k *Kustomize) AddAddonFiles(dest string, target Target, files []string, templateWriter templatewriter.TemplateWriter) error {
+	kustomizationPath, err := KustomizationPath(path.Join(dest, k.overlayPath(target)))
+	if err != nil {
+		return err
+	}
+
+	kustomization, err := yaml.ReadFile(kustomizationPath)
+	if err != nil {
+		return err
+	}
+	resources, err := kustomization.Pipe(yaml.LookupCreate(yaml.SequenceNode, "resources"))
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]bool)
+	for _, resource := range resources.Content() {
+		listed[resource.Value] = true
+	}
+	for _, file := range files {
+		if listed[file] {
+			continue
+		}
+		if err = resources.PipeE(yaml.Append(yaml.NewScalarRNode(file).YNode())); err != nil {
+			return err
+		}
+	}
+
+	content, err := kustomization.String()
+	if err != nil {
+		return err
+	}
+	return templateWriter.WriteFile(kustomizationPath, []byte(content))
+}
+
+// KustomizationPath returns the path of the kustomization in dir
+func KustomizationPath(dir string) (string, error) {
+	for _, name := range kustomizationFileNames {
+		if _, err := os.Stat(path.Join(dir, name)); err == nil {
+			return path.Join(dir, name), nil
+		}
+	}
+	return "", fmt.Errorf("no kustomization found in %s", dir)
+}
+
+// overlayPath returns the target's overlay directory relative to the project, the production overlay by default
+func (k *Kustomize) overlayPath(target Target) string {
+	if target.KustomizeOverlay != "" {
+		return target.KustomizeOverlay
+	}
+	return path.Join(consts.KustomizeOverlaysDir, consts.DefaultKustomizeOverlay)
+}
+
+// unprefixedOverlayFS reads the kustomization of the overlay in overlayDir without its namePrefix and nameSuffix
+type unprefixedOverlayFS struct {
+	filesys.FileSystem
+	overlayDir string
+}
+
+func (fSys unprefixedOverlayFS) ReadFile(filePath string) ([]byte, error) {
+	content, err := fSys.FileSystem.ReadFile(filePath)
+	if err != nil || filepath.Dir(filePath) != fSys.overlayDir || !isKustomizationFile(filepath.Base(filePath)) {
+		return content, err
+	}
+
+	kustomization, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"namePrefix", "nameSuffix"} {
+		if err = kustomization.PipeE(yaml.Clear(field)); err != nil {
+			return nil, err
+		}
+	}
+	unprefixed, err := kustomization.String()
+	return []byte(unprefixed), err
+}
@@ -0,0 +1,55 @@
+package backends
+
+import (
+	"os"
+	"path"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+const manifestsDir = "manifests"
+
+// Manifests deploys with the plain Kubernetes manifests in the project's manifests directory
+type Manifests struct{}
+
+func (m *Manifests) DeployType() string {
+	return "manifests"
+}
+
+func (m *Manifests) Detect(dest string) (bool, error) {
+	_, err := os.Stat(path.Join(dest, manifestsDir))
+	return !os.IsNotExist(err), nil
+}
+
+func (m *Manifests) Dirs(dest string) ([]string, error) {
+	return []string{manifestsDir}, nil
+}
+
+func (m *Manifests) Render(files map[string][]byte) (map[string][]byte, error) {
+	return yamlFiles(files), nil
+}
+
+func (m *Manifests) SetImage(dest string, target Target, image string, templateWriter templatewriter.TemplateWriter) error {
+	return setDeploymentContainerImage(path.Join(dest, manifestsDir, "deployment.yaml"), image, templateWriter)
+}
+
+func (m *Manifests) ReferenceValues(dest string, target Target, resource string, references []Reference) (map[string]string, error) {
+	files, err := readFiles(path.Join(dest, manifestsDir))
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := parseResources(yamlFiles(files))
+	if err != nil {
+		return nil, err
+	}
+	return resolveReferences(nodes, resource, references, defaultNamespace)
+}
+
+func (m *Manifests) AddonDestPath(dest string, target Target) (string, error) {
+	return path.Join(dest, manifestsDir), nil
+}
+
+// AddAddonFiles does nothing, since every manifest in the manifests directory is deployed
+func (m *Manifests) AddAddonFiles(dest string, target Target, files []string, templateWriter templatewriter.TemplateWriter) error {
+	return nil
+}
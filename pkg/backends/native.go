@@ -0,0 +1,44 @@
+package backends
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// setDeploymentContainerImage sets the image of the only container of the Deployment in filePath
+func setDeploymentContainerImage(filePath, productionImage string, templateWriter templatewriter.TemplateWriter) error {
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	k8sObj, _, err := decode(file, nil, nil)
+	if err != nil {
+		return err
+	}
+	deploy, ok := k8sObj.(*appsv1.Deployment)
+	if !ok {
+		return errors.New("could not decode kubernetes deployment")
+	}
+
+	if len(deploy.Spec.Template.Spec.Containers) != 1 {
+		return errors.New("unsupported number of containers defined in the deployment spec")
+	}
+
+	deploy.Spec.Template.Spec.Containers[0].Image = productionImage
+
+	var out bytes.Buffer
+	printer := printers.YAMLPrinter{}
+	if err = printer.PrintObj(deploy, &out); err != nil {
+		return err
+	}
+	return templateWriter.WriteFile(filePath, out.Bytes())
+}
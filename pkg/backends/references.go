@@ -0,0 +1,326 @@
+package backends
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// defaultNamespace is the namespace of resources that don't set one
+const defaultNamespace = "default"
+
+// pathElement is a step of a reference path: a field of a map, or an item of a list selected by its index or by the
+// value of one of its fields
+type pathElement struct {
+	field      string
+	index      int
+	matchField string
+	matchValue string
+}
+
+func (e pathElement) isField() bool {
+	return e.field != ""
+}
+
+func (e pathElement) isMatch() bool {
+	return e.matchField != ""
+}
+
+func (e pathElement) String() string {
+	switch {
+	case e.isField():
+		if strings.ContainsAny(e.field, ".[]") {
+			return "[" + e.field + "]"
+		}
+		return e.field
+	case e.isMatch():
+		return "[" + e.matchField + "=" + e.matchValue + "]"
+	}
+	return "[" + strconv.Itoa(e.index) + "]"
+}
+
+// Validate checks the syntax of the reference's path
+func (r Reference) Validate() error {
+	if _, err := parseReferencePath(r.Path); err != nil {
+		return fmt.Errorf("reference %s: %w", r.Name, err)
+	}
+	return nil
+}
+
+// parseReferencePath parses the path of a reference, made of fields separated by dots and of list selectors in
+// brackets. [0] selects an item of a list by index, [name=http] the item whose name field is http, and
+// [app.kubernetes.io/name] the field of a map whose name has dots, such as a label. A list without a selector
+// stands for its only item.
+func parseReferencePath(p string) ([]pathElement, error) {
+	if p == "" {
+		return nil, errors.New("empty reference path")
+	}
+
+	var elements []pathElement
+	for rest := p; rest != ""; {
+		var element pathElement
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid reference path %s: missing ]", p)
+			}
+			var err error
+			if element, err = parseSelector(rest[1:end]); err != nil {
+				return nil, fmt.Errorf("invalid reference path %s: %w", p, err)
+			}
+			rest = rest[end+1:]
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, fmt.Errorf("invalid reference path %s: expected . or [ after ]", p)
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[]")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid reference path %s: empty field name", p)
+			}
+			element = pathElement{field: rest[:end]}
+			rest = rest[end:]
+		}
+		elements = append(elements, element)
+
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid reference path %s: empty field name", p)
+			}
+		}
+	}
+	return elements, nil
+}
+
+func parseSelector(selector string) (pathElement, error) {
+	if selector == "" {
+		return pathElement{}, errors.New("empty selector []")
+	}
+	if unquoted, ok := unquote(selector); ok {
+		if unquoted == "" {
+			return pathElement{}, errors.New("empty field name")
+		}
+		return pathElement{field: unquoted}, nil
+	}
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 0 {
+			return pathElement{}, fmt.Errorf("negative index [%s]", selector)
+		}
+		return pathElement{index: index}, nil
+	}
+	if field, value, ok := strings.Cut(selector, "="); ok {
+		if field == "" {
+			return pathElement{}, fmt.Errorf("missing field name in selector [%s]", selector)
+		}
+		if unquoted, ok := unquote(value); ok {
+			value = unquoted
+		}
+		return pathElement{matchField: field, matchValue: value}, nil
+	}
+	return pathElement{field: selector}, nil
+}
+
+func unquote(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// resolveReferences reads the references from the resource of nodes they point to. resource is the resource's kind,
+// such as service or Deployment, optionally followed by /name when there are several resources of that kind. A
+// missing metadata.namespace resolves to namespace.
+func resolveReferences(nodes []*yaml.RNode, resource string, references []Reference, namespace string) (map[string]string, error) {
+	node, err := selectResource(nodes, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, reference := range references {
+		value, err := lookupReference(node, reference, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("reference %s: %w", reference.Name, err)
+		}
+		log.Debugf("found reference %s: %s", reference.Name, value)
+		values[reference.Name] = value
+	}
+	return values, nil
+}
+
+// selectResource returns the only resource of nodes matching resource
+func selectResource(nodes []*yaml.RNode, resource string) (*yaml.RNode, error) {
+	kind, name, hasName := strings.Cut(resource, "/")
+	var matches []*yaml.RNode
+	var names []string
+	for _, node := range nodes {
+		if !strings.EqualFold(node.GetKind(), kind) || (hasName && node.GetName() != name) {
+			continue
+		}
+		matches = append(matches, node)
+		names = append(names, node.GetName())
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no %s found in the deployment files", resource)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("found %d resources of kind %s (%s), reference one of them as %s/<name>", len(matches), kind, strings.Join(names, ", "), kind)
+}
+
+// lookupReference returns the value at the reference's path in node. Maps and lists are returned as flow style yaml,
+// such as {app: my-app}.
+func lookupReference(node *yaml.RNode, reference Reference, namespace string) (string, error) {
+	elements, err := parseReferencePath(reference.Path)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := lookupPath(node, elements)
+	if err != nil {
+		// resources without a namespace are created in the namespace they're applied to
+		if reference.Path == "metadata.namespace" {
+			return namespace, nil
+		}
+		return "", err
+	}
+
+	if value.YNode().Kind == yaml.ScalarNode {
+		return value.YNode().Value, nil
+	}
+	flow := value.Copy()
+	flow.YNode().Style = yaml.FlowStyle
+	flowString, err := flow.String()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(flowString), nil
+}
+
+func lookupPath(node *yaml.RNode, elements []pathElement) (*yaml.RNode, error) {
+	walked := ""
+	for _, element := range elements {
+		if element.isField() && node.YNode().Kind == yaml.SequenceNode {
+			items := node.Content()
+			if len(items) != 1 {
+				return nil, fmt.Errorf("%s has %d items, select one with %s[<index>] or %s[<field>=<value>]", walked, len(items), walked, walked)
+			}
+			node = yaml.NewRNode(items[0])
+		}
+
+		var err error
+		switch {
+		case element.isField():
+			node, err = lookupField(node, element.field, walked)
+		case element.isMatch():
+			node, err = lookupMatch(node, element, walked)
+		default:
+			node, err = lookupIndex(node, element.index, walked)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if walked != "" && element.isField() && !strings.HasPrefix(element.String(), "[") {
+			walked += "."
+		}
+		walked += element.String()
+	}
+	return node, nil
+}
+
+func lookupField(node *yaml.RNode, field, walked string) (*yaml.RNode, error) {
+	if node.YNode().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a map", walked)
+	}
+	value := node.Field(field)
+	if value == nil || value.Value.IsNil() || value.Value.IsTaggedNull() {
+		if walked == "" {
+			return nil, fmt.Errorf("%s not found", field)
+		}
+		return nil, fmt.Errorf("%s not found in %s", field, walked)
+	}
+	return value.Value, nil
+}
+
+func lookupIndex(node *yaml.RNode, index int, walked string) (*yaml.RNode, error) {
+	if node.YNode().Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s is not a list", walked)
+	}
+	items := node.Content()
+	if index >= len(items) {
+		return nil, fmt.Errorf("%s has %d items, no item at index %d", walked, len(items), index)
+	}
+	return yaml.NewRNode(items[index]), nil
+}
+
+func lookupMatch(node *yaml.RNode, element pathElement, walked string) (*yaml.RNode, error) {
+	if node.YNode().Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s is not a list", walked)
+	}
+	var matches []*yaml.RNode
+	for _, item := range node.Content() {
+		itemNode := yaml.NewRNode(item)
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if field := itemNode.Field(element.matchField); field != nil && field.Value.YNode().Value == element.matchValue {
+			matches = append(matches, itemNode)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no item of %s has %s %s", walked, element.matchField, element.matchValue)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("%d items of %s have %s %s", len(matches), walked, element.matchField, element.matchValue)
+}
+
+// parseResources parses the Kubernetes resources of rendered, in the order of its keys
+func parseResources(rendered map[string][]byte) ([]*yaml.RNode, error) {
+	var nodes []*yaml.RNode
+	for _, name := range sortedKeys(rendered) {
+		fileNodes, err := kio.FromBytes(rendered[name])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		nodes = append(nodes, fileNodes...)
+	}
+	return nodes, nil
+}
+
+// readFiles returns the files in dir, keyed by their slash separated path relative to dir
+func readFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,133 @@
+package backends
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+const referenceResources = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-app
+spec:
+  template:
+    metadata:
+      labels:
+        app: my-app
+        app.kubernetes.io/name: my-app
+    spec:
+      serviceAccountName: my-app
+      containers:
+        - name: app
+          ports:
+            - name: http
+              containerPort: 8080
+            - name: metrics
+              containerPort: 9090
+        - name: sidecar
+          ports:
+            - containerPort: 15000
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-app
+  namespace: my-namespace
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: other
+`
+
+func TestParseReferencePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []pathElement
+		wantErr  bool
+	}{
+		{path: "metadata.name", expected: []pathElement{{field: "metadata"}, {field: "name"}}},
+		{path: "spec.ports[0].port", expected: []pathElement{{field: "spec"}, {field: "ports"}, {index: 0}, {field: "port"}}},
+		{path: "containers[name=app].ports.[1]", expected: []pathElement{{field: "containers"}, {matchField: "name", matchValue: "app"}, {field: "ports"}, {index: 1}}},
+		{path: "labels[app.kubernetes.io/name]", expected: []pathElement{{field: "labels"}, {field: "app.kubernetes.io/name"}}},
+		{path: `labels["app.kubernetes.io/name"]`, expected: []pathElement{{field: "labels"}, {field: "app.kubernetes.io/name"}}},
+		{path: `ports[name="http"]`, expected: []pathElement{{field: "ports"}, {matchField: "name", matchValue: "http"}}},
+		{path: "", wantErr: true},
+		{path: "metadata.", wantErr: true},
+		{path: ".metadata", wantErr: true},
+		{path: "metadata..name", wantErr: true},
+		{path: "ports[0", wantErr: true},
+		{path: "ports[]", wantErr: true},
+		{path: "ports[-1]", wantErr: true},
+		{path: "ports[=http]", wantErr: true},
+		{path: "ports[0]port", wantErr: true},
+		{path: "ports]", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			elements, err := parseReferencePath(test.path)
+			if test.wantErr {
+				assert.NotNil(t, err)
+				assert.NotNil(t, Reference{Name: "test", Path: test.path}.Validate())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, elements)
+		})
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	nodes, err := kio.FromBytes([]byte(referenceResources))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		resource string
+		path     string
+		expected string
+		wantErr  string
+	}{
+		{name: "service name", resource: "service", path: "metadata.name", expected: "my-app"},
+		{name: "kind is case insensitive", resource: "Service", path: "metadata.namespace", expected: "my-namespace"},
+		{name: "only item of list", resource: "service", path: "spec.ports.port", expected: "80"},
+		{name: "default namespace", resource: "deployment", path: "metadata.namespace", expected: "default"},
+		{name: "container port by index", resource: "deployment", path: "spec.template.spec.containers[0].ports[1].containerPort", expected: "9090"},
+		{name: "container port by name", resource: "deployment", path: "spec.template.spec.containers[name=app].ports[name=http].containerPort", expected: "8080"},
+		{name: "pod label", resource: "deployment", path: "spec.template.metadata.labels[app.kubernetes.io/name]", expected: "my-app"},
+		{name: "pod labels", resource: "deployment", path: "spec.template.metadata.labels", expected: "{app: my-app, app.kubernetes.io/name: my-app}"},
+		{name: "service account of deployment", resource: "deployment", path: "spec.template.spec.serviceAccountName", expected: "my-app"},
+		{name: "service account by name", resource: "serviceaccount/other", path: "metadata.name", expected: "other"},
+		{name: "ambiguous resource", resource: "serviceaccount", path: "metadata.name", wantErr: "found 2 resources of kind serviceaccount (my-app, other), reference one of them as serviceaccount/<name>"},
+		{name: "missing resource", resource: "ingress", path: "metadata.name", wantErr: "no ingress found in the deployment files"},
+		{name: "missing named resource", resource: "serviceaccount/missing", path: "metadata.name", wantErr: "no serviceaccount/missing found in the deployment files"},
+		{name: "ambiguous list", resource: "deployment", path: "spec.template.spec.containers.name", wantErr: "spec.template.spec.containers has 2 items, select one with spec.template.spec.containers[<index>] or spec.template.spec.containers[<field>=<value>]"},
+		{name: "missing field", resource: "deployment", path: "spec.replicas", wantErr: "replicas not found in spec"},
+		{name: "index out of range", resource: "deployment", path: "spec.template.spec.containers[2]", wantErr: "spec.template.spec.containers has 2 items, no item at index 2"},
+		{name: "no matching item", resource: "deployment", path: "spec.template.spec.containers[name=web]", wantErr: "no item of spec.template.spec.containers has name web"},
+		{name: "field of value", resource: "service", path: "metadata.name.first", wantErr: "metadata.name is not a map"},
+		{name: "invalid path", resource: "service", path: "metadata..name", wantErr: "invalid reference path metadata..name: empty field name"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := resolveReferences(nodes, test.resource, []Reference{{Name: "ref", Path: test.path}}, defaultNamespace)
+			if test.wantErr != "" {
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]string{"ref": test.expected}, values)
+		})
+	}
+}
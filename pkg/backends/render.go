@@ -0,0 +1,155 @@
+package backends
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// renderReleaseName is the release name charts are rendered with
+const renderReleaseName = "draft-validation"
+
+// renderHelmCharts renders every chart in files with its default values, keyed by the chart directory
+func renderHelmCharts(files map[string][]byte) (map[string][]byte, error) {
+	rendered := make(map[string][]byte)
+	for filePath := range files {
+		if path.Base(filePath) != chartutil.ChartfileName {
+			continue
+		}
+
+		chartDir := path.Dir(filePath)
+		manifests, err := renderHelmChart(filesUnder(files, chartDir))
+		if err != nil {
+			return nil, fmt.Errorf("rendering helm chart %s: %w", chartDir, err)
+		}
+		rendered[chartDir] = manifests
+	}
+	return rendered, nil
+}
+
+func renderHelmChart(chartFiles map[string][]byte) ([]byte, error) {
+	var bufferedFiles []*loader.BufferedFile
+	for _, name := range sortedKeys(chartFiles) {
+		bufferedFiles = append(bufferedFiles, &loader.BufferedFile{Name: name, Data: chartFiles[name]})
+	}
+	chart, err := loader.LoadFiles(bufferedFiles)
+	if err != nil {
+		return nil, err
+	}
+	return renderChart(chart, chart.Values)
+}
+
+// renderChart renders the manifests of chart with values, after validating them against the chart's
+// values.schema.json
+func renderChart(chrt *chart.Chart, chartValues map[string]interface{}) ([]byte, error) {
+	values, err := chartutil.ToRenderValues(chrt, chartValues, chartutil.ReleaseOptions{Name: renderReleaseName, Namespace: defaultNamespace}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := engine.Render(chrt, values)
+	if err != nil {
+		return nil, err
+	}
+	return joinManifests(templates), nil
+}
+
+// joinManifests joins the non-empty yaml templates rendered by helm into a multi-document yaml, in the order of their
+// names
+func joinManifests(templates map[string]string) []byte {
+	var manifests bytes.Buffer
+	for _, name := range sortedKeys(templates) {
+		if ext := path.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		if strings.TrimSpace(templates[name]) == "" {
+			continue
+		}
+		manifests.WriteString("---\n")
+		manifests.WriteString(templates[name])
+		manifests.WriteString("\n")
+	}
+	return manifests.Bytes()
+}
+
+// buildKustomizations builds every kustomization in files, keyed by the kustomization directory
+func buildKustomizations(files map[string][]byte) (map[string][]byte, error) {
+	fSys := filesys.MakeFsInMemory()
+	for filePath, content := range files {
+		if err := fSys.WriteFile(path.Join("/", filePath), content); err != nil {
+			return nil, err
+		}
+	}
+
+	built := make(map[string][]byte)
+	for filePath := range files {
+		if !isKustomizationFile(path.Base(filePath)) {
+			continue
+		}
+
+		kustomizationDir := path.Dir(filePath)
+		resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, path.Join("/", kustomizationDir))
+		if err != nil {
+			return nil, fmt.Errorf("building kustomization %s: %w", kustomizationDir, err)
+		}
+		manifests, err := resMap.AsYaml()
+		if err != nil {
+			return nil, fmt.Errorf("building kustomization %s: %w", kustomizationDir, err)
+		}
+		built[kustomizationDir] = manifests
+	}
+	return built, nil
+}
+
+// kustomizationFileNames are the names kustomize reads a kustomization from
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func isKustomizationFile(name string) bool {
+	for _, kustomizationName := range kustomizationFileNames {
+		if name == kustomizationName {
+			return true
+		}
+	}
+	return false
+}
+
+func yamlFiles(files map[string][]byte) map[string][]byte {
+	manifests := make(map[string][]byte)
+	for filePath, content := range files {
+		if ext := path.Ext(filePath); ext == ".yaml" || ext == ".yml" {
+			manifests[filePath] = content
+		}
+	}
+	return manifests
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+// filesUnder returns the files in dir, keyed by their path relative to dir
+func filesUnder(files map[string][]byte, dir string) map[string][]byte {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	if dir == "." || dir == "" {
+		prefix = ""
+	}
+
+	dirFiles := make(map[string][]byte)
+	for filePath, content := range files {
+		if strings.HasPrefix(filePath, prefix) {
+			dirFiles[strings.TrimPrefix(filePath, prefix)] = content
+		}
+	}
+	return dirFiles
+}
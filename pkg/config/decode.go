@@ -0,0 +1,20 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeStrict decodes yaml content into out, failing on fields that out doesn't have, such as a misspelled
+// deployVaraibles. Errors include the line of the offending field.
+func DecodeStrict(content []byte, out any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
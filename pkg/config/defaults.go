@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultExpressionRegex matches the expressions of a default value, such as {{APPNAME}} or {{lower APPNAME}}
+var defaultExpressionRegex = regexp.MustCompile(`\{\{\s*(?:([a-z]+)\s+)?([A-Za-z_][A-Za-z0-9_.\-]*)\s*\}\}`)
+
+// defaultFunctions are the functions that can be applied to a variable in a default value expression
+var defaultFunctions = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// References returns the variables the default depends on, its ReferenceVar followed by the variables used in
+// the expressions of its Value
+func (d BuilderVarDefault) References() []string {
+	var refs []string
+	if d.ReferenceVar != "" {
+		refs = append(refs, d.ReferenceVar)
+	}
+	for _, match := range defaultExpressionRegex.FindAllStringSubmatch(d.Value, -1) {
+		refs = append(refs, match[2])
+	}
+	return refs
+}
+
+// ResolveVariableDefaults returns the inputs with the variables that aren't set filled in from their defaults,
+// leaving variables whose default has no value empty.
+// Defaults are evaluated in dependency order, so a default can use the defaults of other variables either
+// through its ReferenceVar or the expressions in its Value, such as {{APPNAME}}-svc or {{lower APPNAME}}.
+func ResolveVariableDefaults(defaults []BuilderVarDefault, inputs map[string]string) (map[string]string, error) {
+	r := newDefaultResolver(defaults, inputs, true)
+	for _, variableDefault := range defaults {
+		value, err := r.resolve(variableDefault.Name)
+		if err != nil {
+			return nil, err
+		}
+		r.values[variableDefault.Name] = value
+	}
+	return r.values, nil
+}
+
+// ResolveVariableDefault returns the default value of a variable while variables are still being prompted for.
+// A ReferenceVar only copies the value of a variable in inputs, falling back to the default's Value otherwise,
+// while the variables used in the Value's expressions are resolved from their own defaults when they aren't set.
+func ResolveVariableDefault(variableName string, defaults []BuilderVarDefault, inputs map[string]string) (string, error) {
+	r := newDefaultResolver(defaults, inputs, false)
+	variableDefault, ok := r.defaults[variableName]
+	if !ok {
+		return "", nil
+	}
+	r.resolving = append(r.resolving, variableName)
+	return r.evaluate(variableDefault)
+}
+
+type defaultResolver struct {
+	defaults map[string]BuilderVarDefault
+	values   map[string]string
+	// resolving is the chain of variables being resolved, used to detect cycles
+	resolving []string
+	// followReferenceVars resolves a ReferenceVar from its own default when it isn't set
+	followReferenceVars bool
+}
+
+func newDefaultResolver(defaults []BuilderVarDefault, inputs map[string]string, followReferenceVars bool) *defaultResolver {
+	r := &defaultResolver{
+		defaults:            make(map[string]BuilderVarDefault, len(defaults)),
+		values:              make(map[string]string, len(inputs)),
+		followReferenceVars: followReferenceVars,
+	}
+	for _, variableDefault := range defaults {
+		r.defaults[variableDefault.Name] = variableDefault
+	}
+	for name, value := range inputs {
+		r.values[name] = value
+	}
+	return r
+}
+
+// resolve returns the value of a variable, evaluating its default when it isn't set
+func (r *defaultResolver) resolve(variableName string) (string, error) {
+	if value := r.values[variableName]; value != "" {
+		return value, nil
+	}
+	variableDefault, ok := r.defaults[variableName]
+	if !ok {
+		return "", nil
+	}
+
+	for i, name := range r.resolving {
+		if name == variableName {
+			cycle := append(append([]string{}, r.resolving[i:]...), variableName)
+			return "", fmt.Errorf("variable defaults have a cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	r.resolving = append(r.resolving, variableName)
+	defer func() { r.resolving = r.resolving[:len(r.resolving)-1] }()
+
+	value, err := r.evaluate(variableDefault)
+	if err != nil {
+		return "", err
+	}
+	if value != "" {
+		log.Debugf("setting default value for %s to %s", variableName, value)
+		r.values[variableName] = value
+	}
+	return value, nil
+}
+
+// evaluate returns the value of a default, from its ReferenceVar when that is set, otherwise from its Value
+func (r *defaultResolver) evaluate(variableDefault BuilderVarDefault) (string, error) {
+	if variableDefault.ReferenceVar != "" {
+		value := r.values[variableDefault.ReferenceVar]
+		if value == "" && r.followReferenceVars {
+			var err error
+			if value, err = r.resolve(variableDefault.ReferenceVar); err != nil {
+				return "", err
+			}
+		}
+		if value != "" {
+			log.Debugf("using value of referenceVar %s for %s", variableDefault.ReferenceVar, variableDefault.Name)
+			return value, nil
+		}
+	}
+	return r.interpolate(variableDefault)
+}
+
+// interpolate replaces the expressions in the default's Value. The default is empty when any of the variables
+// it uses has no value, rather than a partial value such as -svc.
+func (r *defaultResolver) interpolate(variableDefault BuilderVarDefault) (string, error) {
+	matches := defaultExpressionRegex.FindAllStringSubmatchIndex(variableDefault.Value, -1)
+	if len(matches) == 0 {
+		return variableDefault.Value, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		expression := variableDefault.Value[match[0]:match[1]]
+		name := variableDefault.Value[match[4]:match[5]]
+
+		value, err := r.resolve(name)
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			log.Debugf("no value for %s used in the default of %s", name, variableDefault.Name)
+			return "", nil
+		}
+
+		if match[2] >= 0 {
+			function := variableDefault.Value[match[2]:match[3]]
+			apply, ok := defaultFunctions[function]
+			if !ok {
+				return "", fmt.Errorf("unknown function %s in default value %s of %s", function, expression, variableDefault.Name)
+			}
+			value = apply(value)
+		}
+
+		sb.WriteString(variableDefault.Value[last:match[0]])
+		sb.WriteString(value)
+		last = match[1]
+	}
+	sb.WriteString(variableDefault.Value[last:])
+	return sb.String(), nil
+}
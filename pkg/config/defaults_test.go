@@ -0,0 +1,121 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	variableDefault := BuilderVarDefault{
+		Name:         "IMAGE",
+		Value:        "{{ AZURECONTAINERREGISTRY }}.azurecr.io/{{lower CONTAINERNAME}}",
+		ReferenceVar: "IMAGENAME",
+	}
+	assert.Equal(t, []string{"IMAGENAME", "AZURECONTAINERREGISTRY", "CONTAINERNAME"}, variableDefault.References())
+}
+
+func TestResolveVariableDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults []BuilderVarDefault
+		inputs   map[string]string
+		want     map[string]string
+		wantErr  string
+	}{
+		{
+			name: "literal values",
+			defaults: []BuilderVarDefault{
+				{Name: "PORT", Value: "80"},
+				{Name: "NAMESPACE", Value: "default"},
+			},
+			inputs: map[string]string{"PORT": "8080"},
+			want:   map[string]string{"PORT": "8080", "NAMESPACE": "default"},
+		},
+		{
+			name: "referenceVar resolved from a later default",
+			defaults: []BuilderVarDefault{
+				{Name: "SERVICEPORT", Value: "80", ReferenceVar: "PORT"},
+				{Name: "PORT", Value: "8080"},
+			},
+			inputs: map[string]string{},
+			want:   map[string]string{"SERVICEPORT": "8080", "PORT": "8080"},
+		},
+		{
+			name: "expressions and functions",
+			defaults: []BuilderVarDefault{
+				{Name: "SERVICENAME", Value: "{{lower APPNAME}}-svc"},
+				{Name: "IMAGE", Value: "{{AZURECONTAINERREGISTRY}}.azurecr.io/{{upper CONTAINERNAME}}"},
+				{Name: "CONTAINERNAME", Value: "{{ trim APPNAME }}"},
+				{Name: "AZURECONTAINERREGISTRY", Value: "myregistry"},
+			},
+			inputs: map[string]string{"APPNAME": "MyApp"},
+			want: map[string]string{
+				"APPNAME":                "MyApp",
+				"SERVICENAME":            "myapp-svc",
+				"IMAGE":                  "myregistry.azurecr.io/MYAPP",
+				"CONTAINERNAME":          "MyApp",
+				"AZURECONTAINERREGISTRY": "myregistry",
+			},
+		},
+		{
+			name: "missing variable leaves the default empty",
+			defaults: []BuilderVarDefault{
+				{Name: "SERVICENAME", Value: "{{APPNAME}}-svc"},
+			},
+			inputs: map[string]string{},
+			want:   map[string]string{"SERVICENAME": ""},
+		},
+		{
+			name: "cycle",
+			defaults: []BuilderVarDefault{
+				{Name: "A", Value: "{{B}}"},
+				{Name: "B", ReferenceVar: "A"},
+			},
+			inputs:  map[string]string{},
+			wantErr: "variable defaults have a cycle: A -> B -> A",
+		},
+		{
+			name: "self reference",
+			defaults: []BuilderVarDefault{
+				{Name: "A", Value: "{{A}}-a"},
+			},
+			inputs:  map[string]string{},
+			wantErr: "variable defaults have a cycle: A -> A",
+		},
+		{
+			name: "unknown function",
+			defaults: []BuilderVarDefault{
+				{Name: "A", Value: "{{title APPNAME}}"},
+			},
+			inputs:  map[string]string{"APPNAME": "myapp"},
+			wantErr: "unknown function title",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveVariableDefaults(tt.defaults, tt.inputs)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveVariableDefaultIgnoresForwardReferenceVars(t *testing.T) {
+	defaults := []BuilderVarDefault{
+		{Name: "SERVICEPORT", Value: "80", ReferenceVar: "PORT"},
+		{Name: "PORT", Value: "8080"},
+	}
+
+	got, err := ResolveVariableDefault("SERVICEPORT", defaults, map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, "80", got)
+
+	got, err = ResolveVariableDefault("SERVICEPORT", defaults, map[string]string{"PORT": "3000"})
+	assert.Nil(t, err)
+	assert.Equal(t, "3000", got)
+}
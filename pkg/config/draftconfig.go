@@ -1,22 +1,41 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 )
 
-// TODO: remove Name Overrides since we don't need them anymore
 type DraftConfig struct {
+	// Language is the language a Dockerfile pack is for
+	Language         string              `yaml:"language"`
 	DisplayName      string              `yaml:"displayName"`
 	NameOverrides    []FileNameOverride  `yaml:"nameOverrides"`
+	FileConditions   []FileCondition     `yaml:"fileConditions"`
 	Variables        []BuilderVar        `yaml:"variables"`
 	VariableDefaults []BuilderVarDefault `yaml:"variableDefaults"`
 
-	nameOverrideMap map[string]string
+	nameOverrideMap map[string]FileNameOverride
 }
 
+// FileNameOverride renames the template file or directory at Path, relative to the template root.
+// Prefix is prepended to the file name, while Destination replaces the whole output path relative
+// to the destination directory. Destination may contain draft variables such as charts/{{APPNAME}}.
 type FileNameOverride struct {
-	Path   string `yaml:"path"`
-	Prefix string `yaml:"prefix"`
+	Path        string `yaml:"path"`
+	Prefix      string `yaml:"prefix"`
+	Destination string `yaml:"destination"`
+}
+
+// FileCondition includes the template file or directory at Path only when the variable
+// named by Variable is set to one of Values. When Exclude is true the check is inverted,
+// and the file is skipped when the variable matches.
+type FileCondition struct {
+	Path     string   `yaml:"path"`
+	Variable string   `yaml:"variable"`
+	Values   []string `yaml:"values"`
+	Exclude  bool     `yaml:"exclude"`
 }
 
 type BuilderVar struct {
@@ -43,28 +62,100 @@ func (d *DraftConfig) GetVariableExampleValues() map[string][]string {
 	return variableExampleValues
 }
 
+// SetVariableDefaults sets the default values of variables, adding a default rule for variables without one
+func (d *DraftConfig) SetVariableDefaults(values map[string]string) {
+	for name, value := range values {
+		variableExists := false
+		for i, variableDefault := range d.VariableDefaults {
+			if variableDefault.Name == name {
+				variableExists = true
+				d.VariableDefaults[i].Value = value
+				break
+			}
+		}
+		if !variableExists {
+			d.VariableDefaults = append(d.VariableDefaults, BuilderVarDefault{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+}
+
 func (d *DraftConfig) initNameOverrideMap() {
-	d.nameOverrideMap = make(map[string]string)
+	d.nameOverrideMap = make(map[string]FileNameOverride)
 	log.Debug("initializing nameOverrideMap")
-	for _, builderVar := range d.NameOverrides {
-		log.Debugf("mapping path: %s, to prefix %s", builderVar.Path, builderVar.Prefix)
-		d.nameOverrideMap[builderVar.Path] = builderVar.Prefix
+	for _, override := range d.NameOverrides {
+		log.Debugf("mapping path: %s, to prefix: %s, destination: %s", override.Path, override.Prefix, override.Destination)
+		d.nameOverrideMap[override.Path] = override
 	}
 }
 
-func (d *DraftConfig) GetNameOverride(path string) string {
+// GetNameOverride returns the name override for the template path, if one is set
+func (d *DraftConfig) GetNameOverride(path string) (FileNameOverride, bool) {
 	if d.nameOverrideMap == nil {
 		d.initNameOverrideMap()
 	}
-	prefix, ok := d.nameOverrideMap[path]
-	if !ok {
-		return ""
-	}
+	override, ok := d.nameOverrideMap[path]
+	return override, ok
+}
+
+// IsFileIncluded evaluates the file conditions for the template path against the given variable values.
+// Paths without any conditions are always included.
+func (d *DraftConfig) IsFileIncluded(path string, variables map[string]string) bool {
+	for _, condition := range d.FileConditions {
+		if condition.Path != path {
+			continue
+		}
+
+		matched := false
+		for _, value := range condition.Values {
+			if variables[condition.Variable] == value {
+				matched = true
+				break
+			}
+		}
 
-	return prefix
+		if matched == condition.Exclude {
+			log.Debugf("skipping %s as variable %s=%s does not satisfy its file condition", path, condition.Variable, variables[condition.Variable])
+			return false
+		}
+	}
+	return true
 }
 
 // TemplateVariableRecorder is an interface for recording variables that are used read using draft configs
 type TemplateVariableRecorder interface {
 	Record(key, value string)
 }
+
+// Validate checks the config for mistakes that decoding doesn't catch, such as unnamed or duplicate variables
+// and variable defaults that can't be evaluated
+func (d *DraftConfig) Validate() error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, variable := range d.Variables {
+		if variable.Name == "" {
+			errs = append(errs, fmt.Errorf("variables[%d] has no name", i))
+			continue
+		}
+		if seen[variable.Name] {
+			errs = append(errs, fmt.Errorf("variable %s is declared more than once", variable.Name))
+		}
+		seen[variable.Name] = true
+	}
+	for i, variableDefault := range d.VariableDefaults {
+		if variableDefault.Name == "" {
+			errs = append(errs, fmt.Errorf("variableDefaults[%d] has no name", i))
+		}
+	}
+	for i, condition := range d.FileConditions {
+		if condition.Path == "" || condition.Variable == "" {
+			errs = append(errs, fmt.Errorf("fileConditions[%d] needs a path and a variable", i))
+		}
+	}
+	if _, err := ResolveVariableDefaults(d.VariableDefaults, map[string]string{}); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is the subset of JSON Schema used to describe draft's config files
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+}
+
+// GenerateJSONSchema returns the JSON Schema of the yaml documents decoded into v, following the yaml tags of
+// its fields. Structs don't allow additional properties, matching DecodeStrict.
+func GenerateJSONSchema(v any, title string) (*JSONSchema, error) {
+	schema, err := typeSchema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = jsonSchemaVersion
+	schema.Title = title
+	return schema, nil
+}
+
+// MarshalJSONSchema returns the indented JSON of the schema generated for v, ending with a newline
+func MarshalJSONSchema(v any, title string) ([]byte, error) {
+	schema, err := GenerateJSONSchema(v, title)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func typeSchema(t reflect.Type) (*JSONSchema, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		// yaml decodes unquoted scalars such as 80 or true into strings too
+		return &JSONSchema{Type: []string{"string", "number", "boolean"}}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema), AdditionalProperties: false}
+		if err := addStructProperties(schema, t); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// addStructProperties adds the fields of a struct to the schema's properties, merging the fields of inline structs
+func addStructProperties(schema *JSONSchema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// like yaml.v3, embedded structs are decoded even when their type is unexported
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if options == "inline" {
+			if err := addStructProperties(schema, field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			// yaml.v3 uses the lowercased field name for fields without a tag
+			name = strings.ToLower(field.Name)
+		}
+
+		property, err := typeSchema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		schema.Properties[name] = property
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaTestInline struct {
+	Shared string `yaml:"shared"`
+}
+
+type schemaTestConfig struct {
+	schemaTestInline `yaml:",inline"`
+	Name             string            `yaml:"name"`
+	Count            int               `yaml:"count,omitempty"`
+	Enabled          bool              `yaml:"enabled"`
+	Tags             []string          `yaml:"tags"`
+	Labels           map[string]string `yaml:"labels"`
+	Untagged         string
+	Skipped          string `yaml:"-"`
+	unexported       string
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema, err := GenerateJSONSchema(&schemaTestConfig{}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, jsonSchemaVersion, schema.Schema)
+	assert.Equal(t, "test", schema.Title)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, false, schema.AdditionalProperties)
+
+	stringType := []string{"string", "number", "boolean"}
+	assert.Equal(t, map[string]*JSONSchema{
+		"shared":   {Type: stringType},
+		"name":     {Type: stringType},
+		"count":    {Type: "integer"},
+		"enabled":  {Type: "boolean"},
+		"tags":     {Type: "array", Items: &JSONSchema{Type: stringType}},
+		"labels":   {Type: "object", AdditionalProperties: &JSONSchema{Type: stringType}},
+		"untagged": {Type: stringType},
+	}, schema.Properties)
+}
+
+func TestDecodeStrict(t *testing.T) {
+	var cfg DraftConfig
+	assert.Nil(t, DecodeStrict([]byte("displayName: Go\nvariables:\n  - name: PORT\n"), &cfg))
+	assert.Equal(t, "Go", cfg.DisplayName)
+	assert.Equal(t, "PORT", cfg.Variables[0].Name)
+
+	assert.Nil(t, DecodeStrict([]byte(""), &cfg))
+
+	err := DecodeStrict([]byte("displayName: Go\nvariables:\n  - name: PORT\n    descripton: the port\n"), &cfg)
+	assert.ErrorContains(t, err, "line 4: field descripton not found")
+}
+
+func TestDraftConfigValidate(t *testing.T) {
+	valid := DraftConfig{
+		Variables:        []BuilderVar{{Name: "APPNAME"}, {Name: "SERVICENAME"}},
+		VariableDefaults: []BuilderVarDefault{{Name: "SERVICENAME", Value: "{{APPNAME}}-svc"}},
+	}
+	assert.Nil(t, valid.Validate())
+
+	invalid := DraftConfig{
+		Variables:        []BuilderVar{{Name: "APPNAME"}, {Name: "APPNAME"}, {}},
+		VariableDefaults: []BuilderVarDefault{{Name: "A", Value: "{{A}}"}},
+		FileConditions:   []FileCondition{{Path: "charts"}},
+	}
+	err := invalid.Validate()
+	assert.ErrorContains(t, err, "variable APPNAME is declared more than once")
+	assert.ErrorContains(t, err, "variables[2] has no name")
+	assert.ErrorContains(t, err, "fileConditions[0] needs a path and a variable")
+	assert.ErrorContains(t, err, "variable defaults have a cycle: A -> A")
+}
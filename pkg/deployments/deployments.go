@@ -7,7 +7,6 @@ import (
 	"path"
 
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
 	log "github.com/sirupsen/logrus"
 
@@ -35,7 +34,7 @@ func (d *Deployments) DeployTypes() []string {
 	return names
 }
 
-func (d *Deployments) CopyDeploymentFiles(deployType string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+func (d *Deployments) CopyDeploymentFiles(deployType string, environments []string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	val, ok := d.deploys[deployType]
 	if !ok {
 		return fmt.Errorf("deployment type: %s is not currently supported", deployType)
@@ -48,10 +47,61 @@ func (d *Deployments) CopyDeploymentFiles(deployType string, customInputs map[st
 		deployConfig = nil
 	}
 
-	if err := osutil.CopyDir(d.deploymentTemplates, srcDir, d.dest, deployConfig, customInputs, templateWriter); err != nil {
+	if deployConfig != nil {
+		// callers only need to pass the variables they don't want the defaults of
+		var err error
+		if customInputs, err = config.ResolveVariableDefaults(deployConfig.VariableDefaults, customInputs); err != nil {
+			return err
+		}
+	}
+
+	return RenderTemplates(d.deploymentTemplates, srcDir, d.dest, deployConfig, environments, customInputs, templateWriter)
+}
+
+// GeneratedVariables are set by RenderTemplates itself rather than declared by the deployment templates
+var GeneratedVariables = []string{EnvironmentVariable, EnvironmentFilesVariable, envNamespaceVariable, envReplicasVariable, envServiceTypeVariable, HelmValuesSchemaVariable}
+
+// RenderTemplates renders the deployment templates in the srcDir directory of templates into dest, generating the
+// helm values schema when the templates have one, and rendering the per environment templates once per environment
+func RenderTemplates(templates fs.FS, srcDir, dest string, deployConfig *config.DraftConfig, environments []string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+	if hasHelmValuesSchema(templates, srcDir) {
+		valuesSchema, err := generateHelmValuesSchema(templates, srcDir, deployConfig, customInputs)
+		if err != nil {
+			return err
+		}
+		customInputs = maps.Clone(customInputs)
+		customInputs[HelmValuesSchemaVariable] = valuesSchema
+	}
+
+	var environmentInputs []map[string]string
+	if supportsEnvironments(deployConfig) {
+		if len(environments) == 0 {
+			environments = DefaultEnvironments
+		}
+		for _, environment := range environments {
+			inputs, err := GetEnvironmentInputs(environment, customInputs)
+			if err != nil {
+				return err
+			}
+			environmentInputs = append(environmentInputs, inputs)
+		}
+	} else if len(environments) > 0 {
+		log.Warnf("deployment type %s does not support environments, ignoring %v", path.Base(srcDir), environments)
+	}
+
+	baseInputs := maps.Clone(customInputs)
+	baseInputs[EnvironmentFilesVariable] = "false"
+	if err := osutil.CopyDir(templates, srcDir, dest, deployConfig, baseInputs, templateWriter); err != nil {
 		return err
 	}
 
+	for _, inputs := range environmentInputs {
+		log.Debugf("creating %s files for environment %s", path.Base(srcDir), inputs[EnvironmentVariable])
+		if err := osutil.CopyDir(templates, srcDir, dest, deployConfig, inputs, templateWriter); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -68,8 +118,8 @@ func (d *Deployments) loadConfig(lang string) (*config.DraftConfig, error) {
 	}
 
 	var draftConfig config.DraftConfig
-	if err = yaml.Unmarshal(configBytes, &draftConfig); err != nil {
-		return nil, err
+	if err = config.DecodeStrict(configBytes, &draftConfig); err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
 	}
 
 	return &draftConfig, nil
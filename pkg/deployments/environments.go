@@ -0,0 +1,92 @@
+package deployments
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+const (
+	// EnvironmentVariable is set to the environment being rendered, such as the name of a kustomize overlay
+	EnvironmentVariable = "ENVIRONMENT"
+	// EnvironmentFilesVariable is set by RenderTemplates, over any value passed in, to true while rendering the
+	// templates of an environment, such as kustomize overlays, and to false while rendering the templates shared
+	// by all environments. File conditions on it include the templates of each.
+	EnvironmentFilesVariable = "DRAFT_ENVIRONMENT_FILES"
+	envNamespaceVariable     = "ENVNAMESPACE"
+	envReplicasVariable      = "REPLICAS"
+	envImageTagVariable      = "IMAGETAG"
+	envServiceTypeVariable   = "SERVICETYPE"
+	baseNamespaceVariable    = "NAMESPACE"
+)
+
+// DefaultEnvironments are created when no environments are given
+var DefaultEnvironments = []string{"production"}
+
+var (
+	environmentNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// environments not listed keep the replicas of the base
+	defaultReplicas = map[string]string{
+		"staging": "2",
+	}
+	// production services are exposed through an ingress rather than their own load balancer
+	defaultServiceTypes = map[string]string{
+		"prod":       "ClusterIP",
+		"production": "ClusterIP",
+	}
+)
+
+// supportsEnvironments returns whether the deployment type has templates rendered per environment
+func supportsEnvironments(deployConfig *config.DraftConfig) bool {
+	if deployConfig == nil {
+		return false
+	}
+	for _, condition := range deployConfig.FileConditions {
+		if condition.Variable == EnvironmentFilesVariable {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEnvironmentInputs returns the variables used to render the templates of an environment. The replicas,
+// image tag, namespace and service type of an environment can be set with variables scoped to it, such as
+// staging.REPLICAS, staging.IMAGETAG, staging.NAMESPACE or staging.SERVICETYPE. Otherwise the image tag and
+// namespace of the base are used, and the replicas and service type default by environment name.
+func GetEnvironmentInputs(environment string, customInputs map[string]string) (map[string]string, error) {
+	if !environmentNameRegex.MatchString(environment) {
+		return nil, fmt.Errorf("invalid environment name %q, environment names must be lowercase alphanumeric characters or '-'", environment)
+	}
+
+	environmentInputs := maps.Clone(customInputs)
+	environmentInputs[EnvironmentVariable] = environment
+	environmentInputs[EnvironmentFilesVariable] = "true"
+
+	environmentInputs[envReplicasVariable] = "1"
+	if replicas, ok := defaultReplicas[environment]; ok {
+		environmentInputs[envReplicasVariable] = replicas
+	}
+	environmentInputs[envNamespaceVariable] = customInputs[baseNamespaceVariable]
+	environmentInputs[envServiceTypeVariable] = "LoadBalancer"
+	if serviceType, ok := defaultServiceTypes[environment]; ok {
+		environmentInputs[envServiceTypeVariable] = serviceType
+	}
+
+	if replicas := customInputs[environment+"."+envReplicasVariable]; replicas != "" {
+		environmentInputs[envReplicasVariable] = replicas
+	}
+	if imageTag := customInputs[environment+"."+envImageTagVariable]; imageTag != "" {
+		environmentInputs[envImageTagVariable] = imageTag
+	}
+	if namespace := customInputs[environment+"."+baseNamespaceVariable]; namespace != "" {
+		environmentInputs[envNamespaceVariable] = namespace
+	}
+	if serviceType := customInputs[environment+"."+envServiceTypeVariable]; serviceType != "" {
+		environmentInputs[envServiceTypeVariable] = serviceType
+	}
+
+	return environmentInputs, nil
+}
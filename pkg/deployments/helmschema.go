@@ -0,0 +1,215 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/osutil"
+)
+
+const (
+	// HelmValuesSchemaVariable is set to the values.schema.json generated for the chart's values.yaml
+	HelmValuesSchemaVariable = "HELMVALUESSCHEMA"
+	helmValuesFile           = "charts/values.yaml"
+	helmValuesSchemaFile     = "charts/values.schema.json"
+	jsonSchemaDraft          = "http://json-schema.org/draft-07/schema#"
+)
+
+const (
+	variableMarkerPrefix = "__DRAFT_VARIABLE_"
+	variableMarkerSuffix = "__"
+)
+
+var variableMarkerRegex = regexp.MustCompile(`^` + variableMarkerPrefix + `(\w+?)` + variableMarkerSuffix + `$`)
+
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+}
+
+// hasHelmValuesSchema returns whether the deployment template at srcDir includes a values.schema.json to generate
+func hasHelmValuesSchema(templates fs.FS, srcDir string) bool {
+	_, err := fs.Stat(templates, srcDir+"/"+helmValuesSchemaFile)
+	return err == nil
+}
+
+// generateHelmValuesSchema returns the JSON schema of the chart's values.yaml rendered with customInputs
+func generateHelmValuesSchema(templates fs.FS, srcDir string, deployConfig *config.DraftConfig, customInputs map[string]string) (string, error) {
+	valuesTemplate, err := fs.ReadFile(templates, srcDir+"/"+helmValuesFile)
+	if err != nil {
+		return "", err
+	}
+
+	schemaBytes, err := GenerateValuesSchema(valuesTemplate, deployConfig, customInputs)
+	if err != nil {
+		return "", fmt.Errorf("generating helm values schema: %w", err)
+	}
+	return string(schemaBytes), nil
+}
+
+// GenerateValuesSchema returns a JSON schema for a chart's values.yaml template rendered with customInputs.
+// The types come from the rendered values, widened by the example values of the draft variables, and values
+// set from a draft variable are described with the variable's description.
+func GenerateValuesSchema(valuesTemplate []byte, deployConfig *config.DraftConfig, customInputs map[string]string) ([]byte, error) {
+	variables := make(map[string]config.BuilderVar)
+	markers := make(map[string]string)
+	if deployConfig != nil {
+		for _, variable := range deployConfig.Variables {
+			variables[variable.Name] = variable
+			markers[variable.Name] = variableMarkerPrefix + variable.Name + variableMarkerSuffix
+		}
+	}
+
+	// values are matched to the variables they're set from by rendering the template a second time with
+	// each variable replaced by a marker
+	var markedNode, renderedNode yaml.Node
+	if err := yaml.Unmarshal([]byte(osutil.ReplaceVariables(string(valuesTemplate), markers)), &markedNode); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal([]byte(osutil.ReplaceVariables(string(valuesTemplate), customInputs)), &renderedNode); err != nil {
+		return nil, err
+	}
+
+	schema := nodeSchema(documentContent(&markedNode), documentContent(&renderedNode), variables)
+	if schema == nil {
+		schema = &jsonSchema{Type: "object"}
+	}
+	schema.Schema = jsonSchemaDraft
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	if node.Kind == 0 {
+		return nil
+	}
+	return node
+}
+
+// nodeSchema returns the schema of the rendered node, with the marked node at the same position used to look up
+// the draft variable the value was set from
+func nodeSchema(markedNode, renderedNode *yaml.Node, variables map[string]config.BuilderVar) *jsonSchema {
+	if renderedNode == nil {
+		return nil
+	}
+
+	schema := &jsonSchema{}
+	var variable config.BuilderVar
+	fromVariable := false
+	if markedNode != nil && markedNode.Kind == yaml.ScalarNode {
+		if match := variableMarkerRegex.FindStringSubmatch(markedNode.Value); match != nil {
+			variable, fromVariable = variables[match[1]]
+			schema.Description = strings.TrimSpace(variable.Description)
+		}
+	}
+
+	switch renderedNode.Kind {
+	case yaml.AliasNode:
+		return nodeSchema(markedNode, renderedNode.Alias, variables)
+	case yaml.MappingNode:
+		schema.Type = "object"
+		if fromVariable {
+			// maps rendered from a single variable, such as the .env file data, have arbitrary keys
+			schema.AdditionalProperties = &jsonSchema{Type: "string"}
+			return schema
+		}
+		schema.Properties = make(map[string]*jsonSchema)
+		for i := 0; i+1 < len(renderedNode.Content); i += 2 {
+			key := renderedNode.Content[i].Value
+			if propertySchema := nodeSchema(mappingValue(markedNode, key), renderedNode.Content[i+1], variables); propertySchema != nil {
+				schema.Properties[key] = propertySchema
+			}
+		}
+	case yaml.SequenceNode:
+		schema.Type = "array"
+		if len(renderedNode.Content) > 0 {
+			var markedItem *yaml.Node
+			if markedNode != nil && markedNode.Kind == yaml.SequenceNode && len(markedNode.Content) > 0 {
+				markedItem = markedNode.Content[0]
+			}
+			schema.Items = nodeSchema(markedItem, renderedNode.Content[0], variables)
+		}
+	case yaml.ScalarNode:
+		types := []string{scalarType(renderedNode)}
+		if types[0] == "" {
+			// null values can be set to anything
+			return schema
+		}
+		if fromVariable {
+			types = widenScalarTypes(types, variable)
+		}
+		if len(types) == 1 {
+			schema.Type = types[0]
+		} else {
+			schema.Type = types
+		}
+	}
+
+	return schema
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarType(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return ""
+	default:
+		return "string"
+	}
+}
+
+// widenScalarTypes adds the types of the variable's example values, so a value such as a probe port
+// rendered as the port name "http" also accepts a port number
+func widenScalarTypes(types []string, variable config.BuilderVar) []string {
+	for _, exampleValue := range variable.ExampleValues {
+		exampleType := "string"
+		if variable.VarType == "bool" {
+			exampleType = "boolean"
+		} else if _, err := strconv.Atoi(exampleValue); err == nil {
+			exampleType = "integer"
+		}
+
+		found := false
+		for _, t := range types {
+			if t == exampleType || (t == "number" && exampleType == "integer") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			types = append(types, exampleType)
+		}
+	}
+	return types
+}
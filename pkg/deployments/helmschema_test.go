@@ -0,0 +1,60 @@
+package deployments
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+func TestGenerateValuesSchema(t *testing.T) {
+	valuesTemplate := []byte(`replicaCount: 1
+containerPort: {{PORT}}
+image:
+  repository: {{IMAGENAME}}
+  pullPolicy: Always
+securityContext:
+  runAsNonRoot: {{RUNASNONROOT}}
+probe:
+  port: {{PROBEPORT}}
+envConfig: {{ENVCONFIGDATA}}
+tolerations: []
+nodeSelector: ~
+`)
+	deployConfig := &config.DraftConfig{Variables: []config.BuilderVar{
+		{Name: "PORT", Description: "the port exposed in the application"},
+		{Name: "IMAGENAME", Description: " the name of the image"},
+		{Name: "RUNASNONROOT", Description: "run as non-root", VarType: "bool"},
+		{Name: "PROBEPORT", Description: "the probe port", ExampleValues: []string{"http", "8080"}},
+		{Name: "ENVCONFIGDATA", Description: "the env file data"},
+	}}
+	customInputs := map[string]string{"PORT": "8080", "IMAGENAME": "myapp", "RUNASNONROOT": "true", "PROBEPORT": "http", "ENVCONFIGDATA": `{"LOG_LEVEL": "info"}`}
+
+	schemaBytes, err := GenerateValuesSchema(valuesTemplate, deployConfig, customInputs)
+	assert.Nil(t, err)
+
+	var schema jsonSchema
+	assert.Nil(t, json.Unmarshal(schemaBytes, &schema))
+	assert.Equal(t, jsonSchemaDraft, schema.Schema)
+	assert.Equal(t, "object", schema.Type)
+
+	properties := schema.Properties
+	assert.Equal(t, "integer", properties["replicaCount"].Type)
+	assert.Equal(t, "", properties["replicaCount"].Description)
+	assert.Equal(t, "integer", properties["containerPort"].Type)
+	assert.Equal(t, "the port exposed in the application", properties["containerPort"].Description)
+	assert.Equal(t, "string", properties["image"].Properties["repository"].Type)
+	assert.Equal(t, "the name of the image", properties["image"].Properties["repository"].Description)
+	assert.Equal(t, "boolean", properties["securityContext"].Properties["runAsNonRoot"].Type)
+	assert.Equal(t, []interface{}{"string", "integer"}, properties["probe"].Properties["port"].Type)
+	assert.Equal(t, "object", properties["envConfig"].Type)
+	assert.Equal(t, "string", properties["envConfig"].AdditionalProperties.Type)
+	assert.Empty(t, properties["envConfig"].Properties)
+	assert.Equal(t, "array", properties["tolerations"].Type)
+	assert.Nil(t, properties["nodeSelector"].Type)
+
+	_, err = GenerateValuesSchema([]byte("port: [{{PORT}}"), deployConfig, customInputs)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,165 @@
+// Package dockerfile parses Dockerfiles into their instructions, enough to check them without a docker daemon.
+package dockerfile
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// Instruction is a single Dockerfile instruction, with any line continuations joined
+type Instruction struct {
+	// Command is the upper case instruction name, such as FROM or RUN
+	Command string
+	// Args is the rest of the instruction
+	Args string
+	// Line is the 1-based line the instruction starts on
+	Line int
+	// Stage is the 0-based index of the build stage the instruction belongs to. Instructions before
+	// the first FROM, such as global ARGs, have stage -1.
+	Stage int
+}
+
+// Dockerfile is a parsed Dockerfile
+type Dockerfile struct {
+	Instructions []Instruction
+	// Stages is the number of build stages, one per FROM instruction
+	Stages int
+}
+
+// IsDockerfile returns whether the file name is a Dockerfile, either Dockerfile itself or a variant
+// such as Dockerfile.dev or api.Dockerfile
+func IsDockerfile(name string) bool {
+	return name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// Parse parses the instructions of a Dockerfile. Comments, blank lines and parser directives are skipped.
+func Parse(content []byte) *Dockerfile {
+	d := &Dockerfile{}
+	stage := -1
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	var current strings.Builder
+	startLine := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || (line == "" && current.Len() == 0) {
+			continue
+		}
+		if current.Len() == 0 {
+			startLine = lineNumber
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+
+		if instruction, ok := parseInstruction(current.String(), startLine); ok {
+			if instruction.Command == "FROM" {
+				stage++
+				d.Stages++
+			}
+			instruction.Stage = stage
+			d.Instructions = append(d.Instructions, instruction)
+		}
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		if instruction, ok := parseInstruction(current.String(), startLine); ok {
+			instruction.Stage = stage
+			d.Instructions = append(d.Instructions, instruction)
+		}
+	}
+
+	return d
+}
+
+func parseInstruction(line string, lineNumber int) (Instruction, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Instruction{}, false
+	}
+	command, args, _ := strings.Cut(line, " ")
+	return Instruction{
+		Command: strings.ToUpper(command),
+		Args:    strings.Join(strings.Fields(args), " "),
+		Line:    lineNumber,
+	}, true
+}
+
+// FinalStage returns the instructions of the last build stage
+func (d *Dockerfile) FinalStage() []Instruction {
+	var instructions []Instruction
+	for _, instruction := range d.Instructions {
+		if instruction.Stage == d.Stages-1 && instruction.Stage >= 0 {
+			instructions = append(instructions, instruction)
+		}
+	}
+	return instructions
+}
+
+// ExposedPorts returns the ports the final stage exposes, without their protocol
+func (d *Dockerfile) ExposedPorts() []string {
+	var ports []string
+	for _, instruction := range d.FinalStage() {
+		if instruction.Command != "EXPOSE" {
+			continue
+		}
+		for _, port := range strings.Fields(instruction.Args) {
+			port, _, _ = strings.Cut(port, "/")
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// Env returns the value the final stage sets the environment variable name to, in either the
+// ENV name=value or the legacy ENV name value form
+func (d *Dockerfile) Env(name string) (string, bool) {
+	value, found := "", false
+	for _, instruction := range d.FinalStage() {
+		if instruction.Command != "ENV" {
+			continue
+		}
+		if key, legacyValue, _ := strings.Cut(instruction.Args, " "); key == name && !strings.Contains(key, "=") {
+			value, found = strings.Trim(legacyValue, `"'`), true
+			continue
+		}
+		for _, pair := range strings.Fields(instruction.Args) {
+			if key, pairValue, ok := strings.Cut(pair, "="); ok && key == name {
+				value, found = strings.Trim(pairValue, `"'`), true
+			}
+		}
+	}
+	return value, found
+}
+
+// Port returns the port the application listens on: the PORT environment variable of the final stage,
+// or else the first port it exposes. It returns an empty string if neither is set.
+func (d *Dockerfile) Port() string {
+	if port, ok := d.Env("PORT"); ok && port != "" {
+		return port
+	}
+	if ports := d.ExposedPorts(); len(ports) > 0 {
+		return ports[0]
+	}
+	return ""
+}
+
+// User returns the user the final stage runs as, without its group, or an empty string if it doesn't set one
+func (d *Dockerfile) User() string {
+	user := ""
+	for _, instruction := range d.FinalStage() {
+		if instruction.Command == "USER" {
+			user, _, _ = strings.Cut(strings.TrimSpace(instruction.Args), ":")
+		}
+	}
+	return user
+}
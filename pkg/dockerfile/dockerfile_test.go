@@ -0,0 +1,93 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`# syntax=docker/dockerfile:1
+ARG VERSION=1.22
+
+FROM golang:${VERSION} AS builder
+WORKDIR /app
+# build the binary
+RUN go build \
+    -o /app/server \
+    ./cmd/server
+
+from gcr.io/distroless/static:nonroot
+COPY --from=builder /app/server /server
+USER 65532:65532
+ENTRYPOINT ["/server"]
+`)
+
+	d := Parse(content)
+	assert.Equal(t, 2, d.Stages)
+	assert.Equal(t, []Instruction{
+		{Command: "ARG", Args: "VERSION=1.22", Line: 2, Stage: -1},
+		{Command: "FROM", Args: "golang:${VERSION} AS builder", Line: 4, Stage: 0},
+		{Command: "WORKDIR", Args: "/app", Line: 5, Stage: 0},
+		{Command: "RUN", Args: "go build -o /app/server ./cmd/server", Line: 7, Stage: 0},
+		{Command: "FROM", Args: "gcr.io/distroless/static:nonroot", Line: 11, Stage: 1},
+		{Command: "COPY", Args: "--from=builder /app/server /server", Line: 12, Stage: 1},
+		{Command: "USER", Args: "65532:65532", Line: 13, Stage: 1},
+		{Command: "ENTRYPOINT", Args: `["/server"]`, Line: 14, Stage: 1},
+	}, d.Instructions)
+
+	finalStage := d.FinalStage()
+	assert.Len(t, finalStage, 4)
+	assert.Equal(t, "FROM", finalStage[0].Command)
+
+	assert.Empty(t, Parse([]byte("")).FinalStage())
+}
+
+func TestPort(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		exposed    []string
+		port       string
+	}{
+		{name: "legacy env", dockerfile: "FROM alpine\nENV PORT 8080\nEXPOSE 8080/tcp 9090\n", exposed: []string{"8080", "9090"}, port: "8080"},
+		{name: "env pairs", dockerfile: "FROM alpine\nENV HOST=0.0.0.0 PORT=\"3000\"\nEXPOSE 80\n", exposed: []string{"80"}, port: "3000"},
+		{name: "expose only", dockerfile: "FROM alpine\nEXPOSE 5000\n", exposed: []string{"5000"}, port: "5000"},
+		{name: "build stage only", dockerfile: "FROM golang AS build\nENV PORT 80\nEXPOSE 80\nFROM alpine\n"},
+		{name: "no port", dockerfile: "FROM alpine\nENV PORTAL enabled\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Parse([]byte(tt.dockerfile))
+			assert.Equal(t, tt.exposed, d.ExposedPorts())
+			assert.Equal(t, tt.port, d.Port())
+		})
+	}
+}
+
+func TestUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		user       string
+	}{
+		{name: "uid and gid", dockerfile: "FROM alpine\nUSER 65532:65532\n", user: "65532"},
+		{name: "last user", dockerfile: "FROM alpine\nUSER root\nRUN apk add curl\nUSER 101\n", user: "101"},
+		{name: "build stage only", dockerfile: "FROM golang AS build\nUSER 1000\nFROM alpine\n"},
+		{name: "no user", dockerfile: "FROM alpine\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.user, Parse([]byte(tt.dockerfile)).User())
+		})
+	}
+}
+
+func TestIsDockerfile(t *testing.T) {
+	for _, name := range []string{"Dockerfile", "Dockerfile.dev", "api.Dockerfile"} {
+		assert.True(t, IsDockerfile(name), name)
+	}
+	for _, name := range []string{"dockerfile", "Dockerfiles", "main.go", ".dockerignore"} {
+		assert.False(t, IsDockerfile(name), name)
+	}
+}
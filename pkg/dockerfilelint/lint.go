@@ -0,0 +1,249 @@
+// Package dockerfilelint checks Dockerfiles for common issues, such as unpinned base images or running as root.
+package dockerfilelint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/draft/pkg/dockerfile"
+	"github.com/Azure/draft/pkg/policy"
+)
+
+const (
+	RuleAddInsteadOfCopy  = "dockerfile-add-instead-of-copy"
+	RuleUnpinnedBaseImage = "dockerfile-unpinned-base-image"
+	RuleRootUser          = "dockerfile-root-user"
+	RuleExposePort        = "dockerfile-expose-port"
+	RuleAptGetCleanup     = "dockerfile-apt-get-cleanup"
+)
+
+var (
+	aptGetInstall = regexp.MustCompile(`apt-get\s+(-\S+\s+)*install`)
+	archiveSuffix = regexp.MustCompile(`\.(tar|tar\.gz|tgz|tar\.bz2|tbz2|tar\.xz|txz)$`)
+)
+
+// Options configure the checks
+type Options struct {
+	// Ports are the container ports of the deployment, one of which the Dockerfile should EXPOSE.
+	// When empty, only a missing EXPOSE is reported.
+	Ports []string
+}
+
+type check func(source string, d *dockerfile.Dockerfile, opts Options) []policy.Violation
+
+var checks = []check{
+	checkAddInsteadOfCopy,
+	checkUnpinnedBaseImages,
+	checkRootUser,
+	checkExposePort,
+	checkAptGetCleanup,
+}
+
+// Lint returns the issues found in the Dockerfile content read from source
+func Lint(source string, content []byte, opts Options) []policy.Violation {
+	parsed := dockerfile.Parse(content)
+
+	var violations []policy.Violation
+	for _, c := range checks {
+		violations = append(violations, c(source, parsed, opts)...)
+	}
+	return violations
+}
+
+func newViolation(rule string, severity policy.Severity, source string, line int, message string) policy.Violation {
+	return policy.Violation{Rule: rule, Severity: severity, Message: message, Source: source, Line: line}
+}
+
+// checkAddInsteadOfCopy reports ADD instructions that only copy local files, which COPY does more predictably.
+// ADD is still needed to extract local archives.
+func checkAddInsteadOfCopy(source string, d *dockerfile.Dockerfile, _ Options) []policy.Violation {
+	var violations []policy.Violation
+	for _, instruction := range d.Instructions {
+		if instruction.Command != "ADD" {
+			continue
+		}
+		sources := fileSources(instruction.Args)
+		isArchive := false
+		for _, src := range sources {
+			if archiveSuffix.MatchString(src) && !strings.Contains(src, "://") {
+				isArchive = true
+			}
+		}
+		if !isArchive {
+			violations = append(violations, newViolation(RuleAddInsteadOfCopy, policy.SeverityWarning, source, instruction.Line,
+				"use COPY instead of ADD for files and directories, and download remote files with RUN"))
+		}
+	}
+	return violations
+}
+
+// checkUnpinnedBaseImages reports base images without a tag or digest, or tagged latest
+func checkUnpinnedBaseImages(source string, d *dockerfile.Dockerfile, _ Options) []policy.Violation {
+	stageNames := make(map[string]bool)
+	var violations []policy.Violation
+	for _, instruction := range d.Instructions {
+		if instruction.Command != "FROM" {
+			continue
+		}
+		image, stageName := parseFrom(instruction.Args)
+		isStage := stageNames[strings.ToLower(image)]
+		if stageName != "" {
+			stageNames[stageName] = true
+		}
+		if image == "" || image == "scratch" || isStage || strings.Contains(image, "$") {
+			continue
+		}
+		if !isPinned(image) {
+			violations = append(violations, newViolation(RuleUnpinnedBaseImage, policy.SeverityWarning, source, instruction.Line,
+				fmt.Sprintf("base image %s is not pinned, use a specific tag or digest", image)))
+		}
+	}
+	return violations
+}
+
+// checkRootUser reports a final stage that runs as root, either explicitly or because no USER is set.
+// Images tagged nonroot, like the distroless ones, already run as a non-root user.
+func checkRootUser(source string, d *dockerfile.Dockerfile, _ Options) []policy.Violation {
+	if d.Stages == 0 {
+		return nil
+	}
+
+	user, line := stageUser(d, d.Stages-1, 0)
+	switch {
+	case user == "":
+		return []policy.Violation{newViolation(RuleRootUser, policy.SeverityInfo, source, line,
+			"the final stage runs as root, set a non-root USER")}
+	case isRootUser(user):
+		return []policy.Violation{newViolation(RuleRootUser, policy.SeverityInfo, source, line,
+			fmt.Sprintf("the final stage runs as %s, set a non-root USER", user))}
+	}
+	return nil
+}
+
+// checkExposePort reports a final stage without an EXPOSE, or without one matching the deployment's container ports
+func checkExposePort(source string, d *dockerfile.Dockerfile, opts Options) []policy.Violation {
+	exposed := d.ExposedPorts()
+	if len(exposed) == 0 {
+		return []policy.Violation{newViolation(RuleExposePort, policy.SeverityWarning, source, 0,
+			"the final stage has no EXPOSE instruction for the port the application listens on")}
+	}
+	if len(opts.Ports) == 0 {
+		return nil
+	}
+	for _, port := range opts.Ports {
+		for _, exposedPort := range exposed {
+			if port == exposedPort {
+				return nil
+			}
+		}
+	}
+	return []policy.Violation{newViolation(RuleExposePort, policy.SeverityError, source, 0,
+		fmt.Sprintf("EXPOSE %s doesn't match the deployment container port %s", strings.Join(exposed, " "), strings.Join(opts.Ports, ", ")))}
+}
+
+// checkAptGetCleanup reports apt-get installs that leave the package lists in the image layer
+func checkAptGetCleanup(source string, d *dockerfile.Dockerfile, _ Options) []policy.Violation {
+	var violations []policy.Violation
+	for _, instruction := range d.Instructions {
+		if instruction.Command != "RUN" || !aptGetInstall.MatchString(instruction.Args) {
+			continue
+		}
+		if !strings.Contains(instruction.Args, "rm -rf /var/lib/apt/lists") {
+			violations = append(violations, newViolation(RuleAptGetCleanup, policy.SeverityWarning, source, instruction.Line,
+				"remove the apt package lists with rm -rf /var/lib/apt/lists/* in the same RUN as apt-get install"))
+		}
+	}
+	return violations
+}
+
+// parseFrom returns the image and the stage name of the FROM arguments [--platform=<platform>] <image> [AS <name>]
+func parseFrom(args string) (image, stageName string) {
+	var fields []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+		stageName = strings.ToLower(fields[2])
+	}
+	return fields[0], stageName
+}
+
+func isPinned(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	colon := strings.LastIndex(image, ":")
+	if colon < 0 || colon < strings.LastIndex(image, "/") {
+		return false
+	}
+	return image[colon+1:] != "latest"
+}
+
+// stageUser returns the user the stage runs as and the line it is set on. A stage built from an earlier stage
+// inherits its user, and a stage built from a nonroot image runs as nonroot.
+func stageUser(d *dockerfile.Dockerfile, stage, depth int) (string, int) {
+	var image, user string
+	line := 0
+	for _, instruction := range d.Instructions {
+		if instruction.Stage != stage {
+			continue
+		}
+		switch instruction.Command {
+		case "FROM":
+			image, _ = parseFrom(instruction.Args)
+			line = instruction.Line
+		case "USER":
+			user, line = instruction.Args, instruction.Line
+		}
+	}
+	if user != "" || depth > d.Stages {
+		return user, line
+	}
+
+	for _, instruction := range d.Instructions {
+		if instruction.Command != "FROM" || instruction.Stage >= stage {
+			continue
+		}
+		if _, stageName := parseFrom(instruction.Args); stageName != "" && stageName == strings.ToLower(image) {
+			if inherited, _ := stageUser(d, instruction.Stage, depth+1); inherited != "" {
+				return inherited, line
+			}
+		}
+	}
+	if strings.Contains(image, ":nonroot") {
+		return "nonroot", line
+	}
+	return "", line
+}
+
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "root" || name == "0"
+}
+
+// fileSources returns the sources of ADD or COPY arguments, in shell or json form, without flags
+func fileSources(args string) []string {
+	var fields []string
+	if strings.HasPrefix(args, "[") {
+		if err := json.Unmarshal([]byte(args), &fields); err != nil {
+			return nil
+		}
+	} else {
+		for _, field := range strings.Fields(args) {
+			if !strings.HasPrefix(field, "--") {
+				fields = append(fields, field)
+			}
+		}
+	}
+	if len(fields) < 2 {
+		return nil
+	}
+	return fields[:len(fields)-1]
+}
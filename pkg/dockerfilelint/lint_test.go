@@ -0,0 +1,110 @@
+package dockerfilelint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		opts       Options
+		want       []string
+	}{
+		{
+			name:       "clean",
+			dockerfile: "FROM golang:1.22 AS builder\nRUN go build -o /app\nFROM gcr.io/distroless/static-debian12:nonroot\nCOPY --from=builder /app /app\nEXPOSE 8080\n",
+			opts:       Options{Ports: []string{"8080"}},
+		},
+		{
+			name:       "add local files",
+			dockerfile: "FROM alpine:3.18\nADD . /app\nADD app.tar.gz /opt\nUSER 1000\nEXPOSE 80\n",
+			want:       []string{RuleAddInsteadOfCopy},
+		},
+		{
+			name:       "unpinned base images",
+			dockerfile: "FROM golang AS build\nFROM build AS test\nFROM --platform=linux/amd64 alpine:latest\nFROM scratch\nUSER 1000\nEXPOSE 80\n",
+			want:       []string{RuleUnpinnedBaseImage, RuleUnpinnedBaseImage},
+		},
+		{
+			name:       "root user",
+			dockerfile: "FROM alpine:3.18\nUSER 1000\nUSER root:root\nEXPOSE 80\n",
+			want:       []string{RuleRootUser},
+		},
+		{
+			name:       "no user in final stage",
+			dockerfile: "FROM alpine:3.18 AS base\nUSER 1000\nFROM debian:12\nEXPOSE 80\n",
+			want:       []string{RuleRootUser},
+		},
+		{
+			name:       "user inherited from stage",
+			dockerfile: "FROM alpine:3.18 AS base\nUSER 1000\nFROM base\nEXPOSE 80\n",
+		},
+		{
+			name:       "missing expose",
+			dockerfile: "FROM alpine:3.18\nUSER 1000\n",
+			want:       []string{RuleExposePort},
+		},
+		{
+			name:       "expose port mismatch",
+			dockerfile: "FROM alpine:3.18\nUSER 1000\nEXPOSE 80/tcp 443\n",
+			opts:       Options{Ports: []string{"8080"}},
+			want:       []string{RuleExposePort},
+		},
+		{
+			name:       "expose port match",
+			dockerfile: "FROM alpine:3.18\nUSER 1000\nEXPOSE 80/tcp 8080\n",
+			opts:       Options{Ports: []string{"8080"}},
+		},
+		{
+			name:       "apt-get without cleanup",
+			dockerfile: "FROM debian:12\nRUN apt-get update && apt-get -y install curl\nRUN apt-get update \\\n    && apt-get install -y git \\\n    && rm -rf /var/lib/apt/lists/*\nUSER 1000\nEXPOSE 80\n",
+			want:       []string{RuleAptGetCleanup},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range Lint("Dockerfile", []byte(tt.dockerfile), tt.opts) {
+				got = append(got, violation.Rule)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+// TestLintTemplates keeps the Dockerfiles draft generates clean. The default profiles may run as root, which is
+// what the hardened profiles are for.
+func TestLintTemplates(t *testing.T) {
+	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
+	for _, lang := range l.Names() {
+		for _, profile := range []string{"default", "hardened"} {
+			t.Run(lang+"/"+profile, func(t *testing.T) {
+				inputs := map[string]string{}
+				for _, variableDefault := range l.GetConfig(lang).VariableDefaults {
+					inputs[variableDefault.Name] = variableDefault.Value
+				}
+				inputs["PORT"] = "8080"
+				inputs["DOCKERFILE_PROFILE"] = profile
+
+				templateWriter := &writers.FileMapWriter{}
+				assert.Nil(t, l.CreateDockerfileForLanguage(lang, inputs, templateWriter))
+
+				for _, violation := range Lint("Dockerfile", templateWriter.FileMap["Dockerfile"], Options{Ports: []string{"8080"}}) {
+					if profile == "default" && violation.Rule == RuleRootUser {
+						assert.Equal(t, policy.SeverityInfo, violation.Severity)
+						continue
+					}
+					t.Errorf("unexpected violation: %s", violation)
+				}
+			})
+		}
+	}
+}
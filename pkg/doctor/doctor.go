@@ -0,0 +1,337 @@
+// Package doctor checks that the files of a project agree with each other, such as the port the Dockerfile
+// exposes and the ports the deployment and service send traffic to.
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/dockerfile"
+	"github.com/Azure/draft/pkg/policy"
+)
+
+const (
+	// CheckDockerfilePort reports a Dockerfile PORT environment variable that isn't exposed
+	CheckDockerfilePort = "dockerfile-port"
+	// CheckContainerPort reports workloads without a container port for the Dockerfile's port
+	CheckContainerPort = "container-port"
+	// CheckHelmValuesPort reports helm charts whose containerPort value isn't the Dockerfile's port
+	CheckHelmValuesPort = "helm-values-port"
+	// CheckServiceTargetPort reports service target ports that aren't a container port of the selected workloads
+	CheckServiceTargetPort = "service-target-port"
+
+	appDockerfile   = "Dockerfile"
+	chartFile       = "Chart.yaml"
+	valuesFile      = "values.yaml"
+	valuesPortField = "containerPort"
+)
+
+type containerPort struct {
+	name   string
+	number string
+}
+
+type workload struct {
+	resource string
+	labels   map[string]string
+	ports    []containerPort
+}
+
+// Check returns the inconsistencies between the project files, keyed by their path relative to the project.
+// The Dockerfile in the project root is taken as the source of truth for the application's port.
+func Check(files map[string][]byte) ([]policy.Violation, error) {
+	input, err := policy.NewInput(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []policy.Violation
+	appPort := ""
+	if content, ok := input.Dockerfiles[appDockerfile]; ok {
+		parsed := dockerfile.Parse(content)
+		appPort = parsed.Port()
+		violations = append(violations, checkDockerfilePort(parsed)...)
+	}
+
+	chartValuesPorts, chartViolations, err := checkHelmValues(files, appPort)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, chartViolations...)
+
+	nodesBySource := make(map[string][]*yaml.RNode)
+	workloadsByGroup := make(map[string][]workload)
+	for _, source := range sortedKeys(input.Manifests) {
+		nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(input.Manifests[source]), OmitReaderAnnotations: true}).Read()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", source, err)
+		}
+		nodesBySource[source] = nodes
+		workloadsByGroup[sourceGroup(source)] = append(workloadsByGroup[sourceGroup(source)], workloadsOf(nodes)...)
+	}
+
+	for _, source := range sortedKeys(nodesBySource) {
+		nodes := nodesBySource[source]
+		workloads := workloadsOf(nodes)
+
+		// a chart's container port comes from its values, which are already checked
+		if _, hasValuesPort := chartValuesPorts[source]; !hasValuesPort && appPort != "" {
+			for _, w := range workloads {
+				if !hasPortNumber(w.ports, appPort) {
+					violations = append(violations, policy.Violation{
+						Rule:     CheckContainerPort,
+						Severity: policy.SeverityError,
+						Message:  fmt.Sprintf("container ports %s don't include the Dockerfile port %s", portNumbers(w.ports), appPort),
+						Source:   source,
+						Resource: w.resource,
+						Field:    "ports",
+					})
+				}
+			}
+		}
+
+		for _, node := range nodes {
+			if node.GetKind() == "Service" {
+				violations = append(violations, checkServiceTargetPorts(source, node, workloadsByGroup[sourceGroup(source)])...)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// sourceGroup is the set of resources a service can select workloads from: those rendered from the same chart or
+// kustomization, or the manifest files in the same directory
+func sourceGroup(source string) string {
+	if ext := path.Ext(source); ext == ".yaml" || ext == ".yml" {
+		return path.Dir(source)
+	}
+	return source
+}
+
+// ContainerPorts returns the sorted, distinct container port numbers of the workloads in manifests
+func ContainerPorts(manifests map[string][]byte) []string {
+	portSet := make(map[string]bool)
+	for _, source := range sortedKeys(manifests) {
+		nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(manifests[source]), OmitReaderAnnotations: true}).Read()
+		if err != nil {
+			continue
+		}
+		for _, w := range workloadsOf(nodes) {
+			for _, port := range w.ports {
+				portSet[port.number] = true
+			}
+		}
+	}
+	return sortedKeys(portSet)
+}
+
+func checkDockerfilePort(parsed *dockerfile.Dockerfile) []policy.Violation {
+	envPort, ok := parsed.Env("PORT")
+	exposed := parsed.ExposedPorts()
+	if !ok || envPort == "" || len(exposed) == 0 {
+		return nil
+	}
+	for _, port := range exposed {
+		if port == envPort {
+			return nil
+		}
+	}
+	return []policy.Violation{{
+		Rule:     CheckDockerfilePort,
+		Severity: policy.SeverityError,
+		Message:  fmt.Sprintf("ENV PORT %s isn't exposed, the Dockerfile exposes %s", envPort, strings.Join(exposed, ", ")),
+		Source:   appDockerfile,
+	}}
+}
+
+// checkHelmValues compares the containerPort value of every chart with the Dockerfile's port. It returns the
+// containerPort values keyed by chart directory.
+func checkHelmValues(files map[string][]byte, appPort string) (map[string]string, []policy.Violation, error) {
+	valuesPorts := make(map[string]string)
+	var violations []policy.Violation
+	for _, filePath := range sortedKeys(files) {
+		if path.Base(filePath) != chartFile {
+			continue
+		}
+		chartDir := path.Dir(filePath)
+		valuesPath := path.Join(chartDir, valuesFile)
+		values, ok := files[valuesPath]
+		if !ok {
+			continue
+		}
+
+		node, err := yaml.Parse(string(values))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing %s: %w", valuesPath, err)
+		}
+		portField := node.Field(valuesPortField)
+		if portField == nil {
+			continue
+		}
+		valuesPort := portField.Value.YNode().Value
+		valuesPorts[chartDir] = valuesPort
+
+		if appPort != "" && valuesPort != appPort {
+			violations = append(violations, policy.Violation{
+				Rule:     CheckHelmValuesPort,
+				Severity: policy.SeverityError,
+				Message:  fmt.Sprintf("containerPort %s isn't the Dockerfile port %s", valuesPort, appPort),
+				Source:   valuesPath,
+				Field:    valuesPortField,
+			})
+		}
+	}
+	return valuesPorts, violations, nil
+}
+
+func checkServiceTargetPorts(source string, service *yaml.RNode, workloads []workload) []policy.Violation {
+	selector, err := service.Pipe(yaml.Lookup("spec", "selector"))
+	if err != nil || selector == nil {
+		return nil
+	}
+	selectorLabels, err := selector.Map()
+	if err != nil {
+		return nil
+	}
+
+	var selectedPorts []containerPort
+	selected := false
+	for _, w := range workloads {
+		if matchesSelector(w.labels, selectorLabels) {
+			selected = true
+			selectedPorts = append(selectedPorts, w.ports...)
+		}
+	}
+	if !selected {
+		return nil
+	}
+
+	ports, err := service.Pipe(yaml.Lookup("spec", "ports"))
+	if err != nil || ports == nil {
+		return nil
+	}
+	elements, err := ports.Elements()
+	if err != nil {
+		return nil
+	}
+
+	var violations []policy.Violation
+	for i, port := range elements {
+		targetPort := port.Field("targetPort")
+		if targetPort == nil {
+			targetPort = port.Field("port")
+		}
+		if targetPort == nil {
+			continue
+		}
+		target := targetPort.Value.YNode().Value
+		if hasPortNumber(selectedPorts, target) || hasPortName(selectedPorts, target) {
+			continue
+		}
+		violations = append(violations, policy.Violation{
+			Rule:     CheckServiceTargetPort,
+			Severity: policy.SeverityError,
+			Message:  fmt.Sprintf("target port %s isn't a container port of the selected workloads, which expose %s", target, portNumbers(selectedPorts)),
+			Source:   source,
+			Resource: fmt.Sprintf("Service %s", service.GetName()),
+			Field:    fmt.Sprintf("spec.ports[%d].targetPort", i),
+		})
+	}
+	return violations
+}
+
+// workloadsOf returns the resources of nodes with a pod template, or pods, with their pod labels and container ports
+func workloadsOf(nodes []*yaml.RNode) []workload {
+	var workloads []workload
+	for _, node := range nodes {
+		pod := node
+		if node.GetKind() != "Pod" {
+			template, err := node.Pipe(yaml.Lookup("spec", "template"))
+			if err != nil || template == nil {
+				continue
+			}
+			pod = template
+		}
+		containers, err := pod.Pipe(yaml.Lookup("spec", "containers"))
+		if err != nil || containers == nil {
+			continue
+		}
+
+		w := workload{resource: fmt.Sprintf("%s %s", node.GetKind(), node.GetName()), labels: pod.GetLabels()}
+
+		_ = containers.VisitElements(func(container *yaml.RNode) error {
+			ports, err := container.Pipe(yaml.Lookup("ports"))
+			if err != nil || ports == nil {
+				return nil
+			}
+			return ports.VisitElements(func(port *yaml.RNode) error {
+				number := port.Field("containerPort")
+				if number == nil {
+					return nil
+				}
+				p := containerPort{number: number.Value.YNode().Value}
+				if name := port.Field("name"); name != nil {
+					p.name = name.Value.YNode().Value
+				}
+				w.ports = append(w.ports, p)
+				return nil
+			})
+		})
+		workloads = append(workloads, w)
+	}
+	return workloads
+}
+
+func matchesSelector(labels map[string]string, selector map[string]interface{}) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasPortNumber(ports []containerPort, number string) bool {
+	for _, port := range ports {
+		if port.number == number {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPortName(ports []containerPort, name string) bool {
+	for _, port := range ports {
+		if port.name != "" && port.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func portNumbers(ports []containerPort) string {
+	if len(ports) == 0 {
+		return "none"
+	}
+	numbers := make([]string, 0, len(ports))
+	for _, port := range ports {
+		numbers = append(numbers, port.number)
+	}
+	return strings.Join(numbers, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
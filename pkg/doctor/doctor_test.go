@@ -0,0 +1,126 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+const testService = `apiVersion: v1
+kind: Service
+metadata:
+  name: testapp
+spec:
+  selector:
+    app: testapp
+  ports:
+    - port: 80
+      targetPort: web
+    - port: 8443
+      targetPort: 8443
+`
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: testapp
+spec:
+  selector:
+    matchLabels:
+      app: testapp
+  template:
+    metadata:
+      labels:
+        app: testapp
+    spec:
+      containers:
+        - name: testapp
+          image: testapp:v1
+          ports:
+            - name: http
+              containerPort: 8080
+`
+
+func deploymentFiles(t *testing.T, deployType, port string) map[string][]byte {
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, ".")
+	templateWriter := &writers.FileMapWriter{}
+	inputs := map[string]string{"APPNAME": "testapp", "PORT": port, "SERVICEPORT": "80", "NAMESPACE": "default", "IMAGENAME": "testapp", "IMAGETAG": "v1"}
+	deployConfig, err := d.GetConfig(deployType)
+	assert.Nil(t, err)
+	for _, variableDefault := range deployConfig.VariableDefaults {
+		if _, ok := inputs[variableDefault.Name]; !ok {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+	}
+	assert.Nil(t, d.CopyDeploymentFiles(deployType, nil, inputs, templateWriter))
+	return templateWriter.FileMap
+}
+
+func TestCheckGeneratedDeployments(t *testing.T) {
+	tests := []struct {
+		deployType string
+		wantRule   string
+	}{
+		{deployType: "manifests", wantRule: CheckContainerPort},
+		{deployType: "kustomize", wantRule: CheckContainerPort},
+		{deployType: "helm", wantRule: CheckHelmValuesPort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.deployType, func(t *testing.T) {
+			files := deploymentFiles(t, tt.deployType, "8080")
+			files["Dockerfile"] = []byte("FROM alpine:3.18\nENV PORT 8080\nEXPOSE 8080\n")
+
+			violations, err := Check(files)
+			assert.Nil(t, err)
+			assert.Empty(t, violations)
+
+			files["Dockerfile"] = []byte("FROM alpine:3.18\nENV PORT 3000\nEXPOSE 3000\n")
+			violations, err = Check(files)
+			assert.Nil(t, err)
+			if assert.NotEmpty(t, violations) {
+				for _, violation := range violations {
+					assert.Equal(t, tt.wantRule, violation.Rule)
+					assert.Contains(t, violation.Message, "3000")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDockerfilePort(t *testing.T) {
+	violations, err := Check(map[string][]byte{"Dockerfile": []byte("FROM alpine:3.18\nENV PORT 8080\nEXPOSE 80\n")})
+	assert.Nil(t, err)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, CheckDockerfilePort, violations[0].Rule)
+	}
+}
+
+func TestCheckServiceTargetPort(t *testing.T) {
+	violations, err := Check(map[string][]byte{"manifests/app.yaml": []byte(testDeployment + "---\n" + testService)})
+	assert.Nil(t, err)
+
+	var fields []string
+	for _, violation := range violations {
+		assert.Equal(t, CheckServiceTargetPort, violation.Rule)
+		assert.Equal(t, "Service testapp", violation.Resource)
+		fields = append(fields, violation.Field)
+	}
+	assert.Equal(t, []string{"spec.ports[0].targetPort", "spec.ports[1].targetPort"}, fields)
+
+	violations, err = Check(map[string][]byte{"manifests/deployment.yaml": []byte(testDeployment), "manifests/service.yaml": []byte(testService)})
+	assert.Nil(t, err)
+	assert.Len(t, violations, 2, "manifests in the same directory are checked together")
+
+	violations, err = Check(map[string][]byte{"manifests/deployment.yaml": []byte(testDeployment), "other/service.yaml": []byte(testService)})
+	assert.Nil(t, err)
+	assert.Empty(t, violations, "a service is only checked against the workloads deployed with it")
+}
+
+func TestContainerPorts(t *testing.T) {
+	assert.Equal(t, []string{"8080"}, ContainerPorts(map[string][]byte{"a.yaml": []byte(testDeployment), "b.yaml": []byte(testService)}))
+	assert.Empty(t, ContainerPorts(map[string][]byte{"b.yaml": []byte(testService)}))
+}
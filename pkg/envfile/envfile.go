@@ -0,0 +1,162 @@
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const (
+	EnvConfigVariable         = "ENVCONFIG"
+	EnvConfigDataVariable     = "ENVCONFIGDATA"
+	EnvSecretDataVariable     = "ENVSECRETDATA"
+	EnvConfigLiteralsVariable = "ENVCONFIGLITERALS"
+	EnvSecretLiteralsVariable = "ENVSECRETLITERALS"
+)
+
+// FileNames are the env files read from the project directory, in order of preference
+var FileNames = []string{".env", ".env.example"}
+
+var (
+	keyRegex       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+	sensitiveRegex = regexp.MustCompile(`(?i)(secret|passw(or)?d|pwd|token|api_?key|access_?key|private_?key|credential|connection_?string|dsn|auth)`)
+)
+
+// EnvVar is a single key value pair read from an env file
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// IsSensitive returns whether the variable's key looks like it holds a secret
+func (e EnvVar) IsSensitive() bool {
+	return sensitiveRegex.MatchString(e.Key)
+}
+
+// Find returns the path of the first env file found in the dir of the repo, or an empty string if there is none
+func Find(r reporeader.RepoReader, dir string) string {
+	for _, fileName := range FileNames {
+		if filePath := filepath.Join(dir, fileName); r.Exists(filePath) {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// Parse reads the KEY=VALUE pairs of an env file. Blank lines, comments and the export keyword are
+// ignored, and surrounding quotes are removed from values. Later definitions of a key replace earlier ones.
+func Parse(content []byte) ([]EnvVar, error) {
+	vars := make([]EnvVar, 0)
+	indexes := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		if !keyRegex.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNumber, key)
+		}
+
+		envVar := EnvVar{Key: key, Value: parseValue(strings.TrimSpace(value))}
+		if i, ok := indexes[key]; ok {
+			vars[i] = envVar
+			continue
+		}
+		indexes[key] = len(vars)
+		vars = append(vars, envVar)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+func parseValue(value string) string {
+	if len(value) >= 2 {
+		quote := value[0]
+		if (quote == '"' || quote == '\'') && value[len(value)-1] == quote {
+			return value[1 : len(value)-1]
+		}
+	}
+	// an unquoted value ends at an inline comment
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// Read finds and parses the env file in the dir of the repo, returning nil when there isn't one
+func Read(r reporeader.RepoReader, dir string) ([]EnvVar, error) {
+	fileName := Find(r, dir)
+	if fileName == "" {
+		return nil, nil
+	}
+
+	content, err := r.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", fileName, err)
+	}
+	vars, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", fileName, err)
+	}
+	log.Debugf("read %d variables from %s", len(vars), fileName)
+	return vars, nil
+}
+
+// ToTemplateVariables converts the env vars into the draft variables used by the deployment templates.
+// Sensitive keys go into the secret with empty values, since secrets must not be written into the repo,
+// and all other keys go into the config map. Maps and lists are encoded as JSON, which is valid inline YAML.
+func ToTemplateVariables(vars []EnvVar) (map[string]string, error) {
+	configData := make(map[string]string)
+	secretData := make(map[string]string)
+	configLiterals := make([]string, 0)
+	secretLiterals := make([]string, 0)
+	for _, envVar := range vars {
+		if envVar.IsSensitive() {
+			secretData[envVar.Key] = ""
+			secretLiterals = append(secretLiterals, envVar.Key+"=")
+			continue
+		}
+		configData[envVar.Key] = envVar.Value
+		configLiterals = append(configLiterals, envVar.Key+"="+envVar.Value)
+	}
+	sort.Strings(configLiterals)
+	sort.Strings(secretLiterals)
+
+	templateVariables := map[string]string{
+		EnvConfigVariable: fmt.Sprintf("%t", len(vars) > 0),
+	}
+	for name, value := range map[string]interface{}{
+		EnvConfigDataVariable:     configData,
+		EnvSecretDataVariable:     secretData,
+		EnvConfigLiteralsVariable: configLiterals,
+		EnvSecretLiteralsVariable: secretLiterals,
+	} {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s: %w", name, err)
+		}
+		templateVariables[name] = string(encoded)
+	}
+	return templateVariables, nil
+}
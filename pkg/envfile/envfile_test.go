@@ -0,0 +1,97 @@
+package envfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`# app config
+LOG_LEVEL=info
+export PORT=8080
+GREETING="hello # world"
+NAME='draft'
+TIMEOUT=30 # seconds
+
+DATABASE_PASSWORD=hunter2
+LOG_LEVEL=debug
+`)
+	vars, err := Parse(content)
+	assert.Nil(t, err)
+	assert.Equal(t, []EnvVar{
+		{Key: "LOG_LEVEL", Value: "debug"},
+		{Key: "PORT", Value: "8080"},
+		{Key: "GREETING", Value: "hello # world"},
+		{Key: "NAME", Value: "draft"},
+		{Key: "TIMEOUT", Value: "30"},
+		{Key: "DATABASE_PASSWORD", Value: "hunter2"},
+	}, vars)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("LOG_LEVEL=info\nnot a variable\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = Parse([]byte("1KEY=value\n"))
+	assert.ErrorContains(t, err, "invalid key")
+}
+
+func TestIsSensitive(t *testing.T) {
+	for key, sensitive := range map[string]bool{
+		"DATABASE_PASSWORD": true,
+		"API_KEY":           true,
+		"GITHUB_TOKEN":      true,
+		"CLIENT_SECRET":     true,
+		"DATABASE_DSN":      true,
+		"LOG_LEVEL":         false,
+		"PORT":              false,
+		"DATABASE_HOST":     false,
+	} {
+		assert.Equal(t, sensitive, EnvVar{Key: key}.IsSensitive(), key)
+	}
+}
+
+func TestRead(t *testing.T) {
+	vars, err := Read(reporeader.FakeRepoReader{Files: map[string][]byte{"main.go": []byte("")}}, ".")
+	assert.Nil(t, err)
+	assert.Nil(t, vars)
+
+	vars, err = Read(reporeader.FakeRepoReader{Files: map[string][]byte{
+		".env.example": []byte("FROM_EXAMPLE=true"),
+		".env":         []byte("FROM_ENV=true"),
+	}}, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, []EnvVar{{Key: "FROM_ENV", Value: "true"}}, vars)
+
+	vars, err = Read(reporeader.FakeRepoReader{Files: map[string][]byte{
+		".env":             []byte("FROM_ROOT=true"),
+		"app/.env.example": []byte("FROM_APP=true"),
+	}}, "app")
+	assert.Nil(t, err)
+	assert.Equal(t, []EnvVar{{Key: "FROM_APP", Value: "true"}}, vars)
+}
+
+func TestToTemplateVariables(t *testing.T) {
+	templateVariables, err := ToTemplateVariables([]EnvVar{
+		{Key: "LOG_LEVEL", Value: "info"},
+		{Key: "API_KEY", Value: "abc123"},
+		{Key: "GREETING", Value: `say "hi"`},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		EnvConfigVariable:         "true",
+		EnvConfigDataVariable:     `{"GREETING":"say \"hi\"","LOG_LEVEL":"info"}`,
+		EnvSecretDataVariable:     `{"API_KEY":""}`,
+		EnvConfigLiteralsVariable: `["GREETING=say \"hi\"","LOG_LEVEL=info"]`,
+		EnvSecretLiteralsVariable: `["API_KEY="]`,
+	}, templateVariables)
+
+	templateVariables, err = ToTemplateVariables(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "false", templateVariables[EnvConfigVariable])
+	assert.Equal(t, "{}", templateVariables[EnvConfigDataVariable])
+	assert.Equal(t, "[]", templateVariables[EnvSecretLiteralsVariable])
+}
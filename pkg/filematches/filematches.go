@@ -2,9 +2,12 @@ package filematches
 
 import (
 	"errors"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/instrumenta/kubeval/kubeval"
 )
@@ -97,21 +100,57 @@ func SearchDirectory(dest string) (bool, bool, error) {
 
 	// recursive directory search for valid yaml files
 	fileMatches := createK8sFileMatches(dest)
-	_, err = FindDraftDeploymentFiles(dest)
-	hasDeploymentFiles := fileMatches.hasDeploymentFiles() || err == nil
-	return hasDockerFile, hasDeploymentFiles, nil
+	return hasDockerFile, fileMatches.hasDeploymentFiles(), nil
 }
 
-func FindDraftDeploymentFiles(dest string) (deploymentType string, err error) {
-	if _, err := os.Stat(dest + "/charts"); !os.IsNotExist(err) {
-		return "helm", nil
+// FindKustomizeOverlays returns the sorted names of the kustomize overlays in dest, or nil if dest has no overlays directory
+func FindKustomizeOverlays(dest string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dest, "overlays"))
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
-	if _, err := os.Stat(dest + "/overlays"); !os.IsNotExist(err) {
-		return "kustomize", nil
+	if err != nil {
+		return nil, err
 	}
-	if _, err := os.Stat(dest + "/manifests"); !os.IsNotExist(err) {
-		return "manifests", nil
+
+	overlays := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			overlays = append(overlays, entry.Name())
+		}
 	}
+	sort.Strings(overlays)
+	return overlays, nil
+}
 
-	return "", errors.New("no supported deployment files found")
+// FindHelmCharts returns the sorted paths, relative to dest, of the helm charts in dest found by their Chart.yaml.
+// Hidden directories and the subcharts of a chart are skipped.
+func FindHelmCharts(dest string) ([]string, error) {
+	var charts []string
+	err := filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dest && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "Chart.yaml")); err != nil {
+			return nil
+		}
+
+		chart, err := filepath.Rel(dest, path)
+		if err != nil {
+			return err
+		}
+		charts = append(charts, filepath.ToSlash(chart))
+		return filepath.SkipDir
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sort.Strings(charts)
+	return charts, err
 }
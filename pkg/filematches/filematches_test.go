@@ -160,3 +160,37 @@ func TestSearchDirectoryWithDockerfile(t *testing.T) {
 	}
 	assert.False(t, hasDockerFile, "should not have Dockerfile")
 }
+
+func TestFindKustomizeOverlays(t *testing.T) {
+	dir := t.TempDir()
+
+	overlays, err := FindKustomizeOverlays(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, overlays)
+
+	for _, overlay := range []string{"staging", "dev", "prod"} {
+		assert.Nil(t, os.MkdirAll(dir+"/overlays/"+overlay, 0755))
+	}
+	assert.Nil(t, os.WriteFile(dir+"/overlays/README.md", []byte(""), 0644))
+
+	overlays, err = FindKustomizeOverlays(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "prod", "staging"}, overlays)
+}
+
+func TestFindHelmCharts(t *testing.T) {
+	dir := t.TempDir()
+
+	charts, err := FindHelmCharts(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, charts)
+
+	for _, chart := range []string{"charts/web", "charts/api", "charts/api/charts/subchart", ".git/charts/ignored", "deploy"} {
+		assert.Nil(t, os.MkdirAll(dir+"/"+chart, 0755))
+		assert.Nil(t, os.WriteFile(dir+"/"+chart+"/Chart.yaml", []byte("name: test"), 0644))
+	}
+
+	charts, err = FindHelmCharts(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"charts/api", "charts/web", "deploy"}, charts)
+}
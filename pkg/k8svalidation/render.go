@@ -0,0 +1,39 @@
+package k8svalidation
+
+import (
+	"path"
+
+	"github.com/Azure/draft/pkg/backends"
+)
+
+// RenderManifests renders the files of a project into the resources they produce, keyed by their source: the chart or
+// kustomization directory they were rendered from, or the path of a plain manifest. The other files in charts and
+// kustomization directories aren't treated as manifests, and files that aren't yaml are ignored.
+func RenderManifests(files map[string][]byte) (map[string][]byte, error) {
+	manifests := make(map[string][]byte)
+	for _, backend := range backends.All() {
+		rendered, err := backend.Render(files)
+		if err != nil {
+			return nil, err
+		}
+		// the backends come in detection order, so the files of charts and kustomizations are rendered by their own
+		// backend before the manifests backend sees them as plain manifests
+		for source, content := range rendered {
+			if !isInSourceDir(source, manifests) {
+				manifests[source] = content
+			}
+		}
+	}
+	return manifests, nil
+}
+
+func isInSourceDir(filePath string, sources map[string][]byte) bool {
+	for dir := path.Dir(filePath); ; dir = path.Dir(dir) {
+		if _, ok := sources[dir]; ok {
+			return true
+		}
+		if dir == "." || dir == "/" {
+			return false
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package k8svalidation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+const (
+	gvkExtension        = "x-kubernetes-group-version-kind"
+	intOrStringSchema   = "io.k8s.apimachinery.pkg.util.intstr.IntOrString"
+	quantitySchema      = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+	definitionRefPrefix = "#/definitions/"
+)
+
+// schemas holds the Kubernetes OpenAPI definitions bundled with kustomize, converted to JSON schema.
+// Loading them takes a moment, so it's only done once the first resource is validated.
+type schemas struct {
+	once        sync.Once
+	err         error
+	definitions map[string]interface{}
+	// definitionNames maps apiVersion/kind to the name of its definition
+	definitionNames map[string]string
+
+	mu       sync.Mutex
+	compiled map[string]*gojsonschema.Schema
+}
+
+var bundledSchemas = &schemas{}
+
+// KubernetesVersion returns the version of Kubernetes the bundled schemas are from
+func KubernetesVersion() string {
+	return openapi.GetSchemaVersion()
+}
+
+func (s *schemas) load() error {
+	s.once.Do(func() {
+		definitionsJSON, err := json.Marshal(openapi.Schema().Definitions)
+		if err != nil {
+			s.err = fmt.Errorf("loading kubernetes schemas: %w", err)
+			return
+		}
+		if err = json.Unmarshal(definitionsJSON, &s.definitions); err != nil {
+			s.err = fmt.Errorf("loading kubernetes schemas: %w", err)
+			return
+		}
+
+		s.definitionNames = make(map[string]string)
+		for name, definition := range s.definitions {
+			definitionMap, ok := definition.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, gvk := range groupVersionKinds(definitionMap) {
+				s.definitionNames[gvk] = name
+			}
+			// resources are validated strictly, so misspelled or misplaced fields aren't silently dropped
+			if _, hasProperties := definitionMap["properties"]; hasProperties {
+				if _, ok := definitionMap["additionalProperties"]; !ok {
+					definitionMap["additionalProperties"] = false
+				}
+			}
+		}
+
+		// the openapi definitions only declare these as strings, while the api server also accepts numbers
+		s.definitions[intOrStringSchema] = map[string]interface{}{"type": []string{"string", "integer"}}
+		s.definitions[quantitySchema] = map[string]interface{}{"type": []string{"string", "number"}}
+
+		s.compiled = make(map[string]*gojsonschema.Schema)
+	})
+	return s.err
+}
+
+// schemaFor returns the schema of the apiVersion and kind, or nil if there is no bundled schema for it,
+// such as for custom resources
+func (s *schemas) schemaFor(apiVersion, kind string) (*gojsonschema.Schema, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	name, ok := s.definitionNames[apiVersion+"/"+kind]
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.compiled[name]; ok {
+		return schema, nil
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(map[string]interface{}{
+		"definitions": s.definitions,
+		"$ref":        definitionRefPrefix + name,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("compiling schema for %s %s: %w", apiVersion, kind, err)
+	}
+	s.compiled[name] = schema
+	return schema, nil
+}
+
+// groupVersionKinds returns the apiVersion/kind keys of the resources a definition describes
+func groupVersionKinds(definition map[string]interface{}) []string {
+	gvks, ok := definition[gvkExtension].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for _, gvk := range gvks {
+		gvkMap, ok := gvk.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := gvkMap["group"].(string)
+		version, _ := gvkMap["version"].(string)
+		kind, _ := gvkMap["kind"].(string)
+		apiVersion := version
+		if group != "" {
+			apiVersion = group + "/" + version
+		}
+		keys = append(keys, apiVersion+"/"+kind)
+	}
+	return keys
+}
@@ -0,0 +1,128 @@
+// Package k8svalidation validates generated Kubernetes resources offline, against the Kubernetes OpenAPI
+// schemas bundled with kustomize.
+package k8svalidation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/xeipuuv/gojsonschema"
+	"golang.org/x/exp/maps"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/backends"
+)
+
+// ValidationError is a schema violation in a generated resource
+type ValidationError struct {
+	// Source is the file, chart or kustomization the resource was generated from
+	Source string
+	Kind   string
+	Name   string
+	// Field is the path of the invalid field, such as spec.replicas
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	resource := e.Kind
+	if e.Name != "" {
+		resource = fmt.Sprintf("%s %s", e.Kind, e.Name)
+	}
+	if e.Field == "" || e.Field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		return fmt.Sprintf("%s: %s: %s", e.Source, resource, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", e.Source, resource, e.Field, e.Message)
+}
+
+// ValidateManifests validates each resource in the multi-document yaml manifests against the bundled schemas.
+// Resources without a bundled schema, such as custom resources, are skipped.
+func ValidateManifests(source string, manifests []byte) ([]ValidationError, error) {
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(manifests), OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", source, err)
+	}
+
+	var validationErrors []ValidationError
+	for _, node := range nodes {
+		nodeErrors, err := validateResource(source, node)
+		if err != nil {
+			return nil, err
+		}
+		validationErrors = append(validationErrors, nodeErrors...)
+	}
+	return validationErrors, nil
+}
+
+func validateResource(source string, node *yaml.RNode) ([]ValidationError, error) {
+	apiVersion, kind := node.GetApiVersion(), node.GetKind()
+	if apiVersion == "" || kind == "" {
+		return []ValidationError{{Source: source, Kind: "resource", Name: node.GetName(), Message: "apiVersion and kind are required"}}, nil
+	}
+
+	schema, err := bundledSchemas.schemaFor(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		log.Debugf("no schema found for %s %s in %s, skipping validation", apiVersion, kind, source)
+		return nil, nil
+	}
+
+	resourceJSON, err := node.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("converting %s %s in %s to json: %w", kind, node.GetName(), source, err)
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(resourceJSON))
+	if err != nil {
+		return nil, fmt.Errorf("validating %s %s in %s: %w", kind, node.GetName(), source, err)
+	}
+
+	var validationErrors []ValidationError
+	for _, resultError := range result.Errors() {
+		validationErrors = append(validationErrors, ValidationError{
+			Source:  source,
+			Kind:    kind,
+			Name:    node.GetName(),
+			Field:   resultError.Field(),
+			Message: resultError.Description(),
+		})
+	}
+	return validationErrors, nil
+}
+
+// ValidateDeploymentFiles renders the generated files of the deployment type and validates the resulting resources.
+// Helm charts are rendered with their default values, every kustomization is built, and manifests are validated as
+// they are. files maps the paths of the generated files, relative to the project directory, to their content.
+func ValidateDeploymentFiles(deployType string, files map[string][]byte) error {
+	backend, err := backends.Get(deployType)
+	if err != nil {
+		return err
+	}
+	sources, err := backend.Render(files)
+	if err != nil {
+		return err
+	}
+
+	var validationErrors []error
+	for _, source := range sortedKeys(sources) {
+		sourceErrors, err := ValidateManifests(source, sources[source])
+		if err != nil {
+			return err
+		}
+		for _, sourceError := range sourceErrors {
+			validationErrors = append(validationErrors, sourceError)
+		}
+	}
+	return errors.Join(validationErrors...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+package k8svalidation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+const validDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: testapp
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: testapp
+  template:
+    metadata:
+      labels:
+        app: testapp
+    spec:
+      containers:
+        - name: testapp
+          image: testapp:latest
+          ports:
+            - containerPort: 80
+              name: http
+          readinessProbe:
+            httpGet:
+              path: /
+              port: http
+          resources:
+            limits:
+              cpu: 1
+              memory: 512Mi
+`
+
+func TestValidateManifests(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifests string
+		errors    []string
+	}{
+		{
+			name:      "valid deployment",
+			manifests: validDeployment,
+		},
+		{
+			name:      "invalid field type",
+			manifests: validDeployment + "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: testapp\nspec:\n  ports:\n    - port: eighty\n",
+			errors:    []string{"test.yaml: Service testapp: spec.ports.0.port: Invalid type. Expected: integer, given: string"},
+		},
+		{
+			name:      "unknown field",
+			manifests: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: testapp\ndatas:\n  KEY: value\n",
+			errors:    []string{"test.yaml: ConfigMap testapp: Additional property datas is not allowed"},
+		},
+		{
+			name:      "missing kind",
+			manifests: "apiVersion: v1\nmetadata:\n  name: testapp\n",
+			errors:    []string{"test.yaml: resource testapp: apiVersion and kind are required"},
+		},
+		{
+			name:      "custom resource without schema",
+			manifests: "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: testapp\nspec:\n  anything: true\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validationErrors, err := ValidateManifests("test.yaml", []byte(tt.manifests))
+			assert.Nil(t, err)
+			var messages []string
+			for _, validationError := range validationErrors {
+				messages = append(messages, validationError.Error())
+			}
+			assert.Equal(t, tt.errors, messages)
+		})
+	}
+
+	_, err := ValidateManifests("test.yaml", []byte("apiVersion: v1\nkind: [\n"))
+	assert.NotNil(t, err)
+}
+
+func TestValidateDeploymentFiles(t *testing.T) {
+	inputs := map[string]string{
+		"APPNAME":       "testapp",
+		"PORT":          "8080",
+		"SERVICEPORT":   "80",
+		"NAMESPACE":     "testapp",
+		"IMAGENAME":     "testapp",
+		"IMAGETAG":      "latest",
+		"PROBEPATH":     "/",
+		"PROBEPORT":     "http",
+		"CPUREQ":        "100m",
+		"CPULIMIT":      "500m",
+		"MEMREQ":        "128Mi",
+		"MEMLIMIT":      "512Mi",
+		"RUNASNONROOT":  "true",
+		"ENVCONFIG":     "true",
+		"ENVCONFIGDATA": `{"LOG_LEVEL": "info"}`,
+		"ENVSECRETDATA": `{"API_TOKEN": ""}`,
+
+		"ENVCONFIGLITERALS": `["LOG_LEVEL=info"]`,
+		"ENVSECRETLITERALS": `["API_TOKEN="]`,
+	}
+
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			files := renderDeploymentFiles(t, deployType, []string{"dev", "production"}, inputs)
+			assert.Nil(t, ValidateDeploymentFiles(deployType, files))
+		})
+	}
+
+	invalidInputs := make(map[string]string)
+	for k, v := range inputs {
+		invalidInputs[k] = v
+	}
+	invalidInputs["dev.REPLICAS"] = "two"
+	files := renderDeploymentFiles(t, "kustomize", []string{"dev"}, invalidInputs)
+	err := ValidateDeploymentFiles("kustomize", files)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "overlays/dev: Deployment dev-testapp: spec.replicas: Invalid type. Expected: integer, given: string")
+
+	invalidInputs["SERVICEPORT"] = "http-port"
+	files = renderDeploymentFiles(t, "manifests", nil, invalidInputs)
+	err = ValidateDeploymentFiles("manifests", files)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "manifests/service.yaml: Service testapp: spec.ports.0.port")
+
+	assert.NotNil(t, ValidateDeploymentFiles("unsupported", files))
+}
+
+func renderDeploymentFiles(t *testing.T, deployType string, environments []string, inputs map[string]string) map[string][]byte {
+	templateWriter := &writers.FileMapWriter{}
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "/project")
+	assert.Nil(t, d.CopyDeploymentFiles(deployType, environments, inputs, templateWriter))
+
+	files := make(map[string][]byte)
+	for filePath, content := range templateWriter.FileMap {
+		relPath, err := filepath.Rel("/project", filePath)
+		assert.Nil(t, err)
+		files[relPath] = content
+	}
+	return files
+}
@@ -0,0 +1,60 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const CMAKE_LISTS_FILE = "CMakeLists.txt"
+
+var (
+	cmakeExecutableRegex = regexp.MustCompile(`(?i)add_executable\(\s*([\w.\-${}]+)`)
+	cmakeProjectRegex    = regexp.MustCompile(`(?i)project\(\s*([\w.\-]+)`)
+)
+
+type CMakeExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*CMakeExtractor) GetName() string {
+	return "cmake"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*CMakeExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "cpp"
+}
+
+// ReadDefaults reads the first executable target from the root CMakeLists.txt
+func (*CMakeExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	if !r.Exists(CMAKE_LISTS_FILE) {
+		return extractedValues, nil
+	}
+
+	content, err := r.ReadFile(CMAKE_LISTS_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", CMAKE_LISTS_FILE, err)
+	}
+
+	match := cmakeExecutableRegex.FindSubmatch(content)
+	if match == nil {
+		return extractedValues, nil
+	}
+	executable := string(match[1])
+	// targets are commonly named after the project
+	if executable == "${PROJECT_NAME}" {
+		project := cmakeProjectRegex.FindSubmatch(content)
+		if project == nil {
+			return extractedValues, nil
+		}
+		executable = string(project[1])
+	}
+	extractedValues["EXECUTABLE"] = executable
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &CMakeExtractor{}
@@ -0,0 +1,56 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestCMakeExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract executable",
+			files: map[string][]byte{
+				"CMakeLists.txt": []byte("cmake_minimum_required(VERSION 3.20)\nproject(server CXX)\nadd_executable(http-server main.cpp)\n"),
+			},
+			want: map[string]string{
+				"EXECUTABLE": "http-server",
+			},
+		},
+		{
+			name: "extract executable named after the project",
+			files: map[string][]byte{
+				"CMakeLists.txt": []byte("cmake_minimum_required(VERSION 3.20)\nproject(server CXX)\nadd_executable(${PROJECT_NAME} main.cpp)\n"),
+			},
+			want: map[string]string{
+				"EXECUTABLE": "server",
+			},
+		},
+		{
+			name: "library only",
+			files: map[string][]byte{
+				"CMakeLists.txt": []byte("project(lib C)\nadd_library(lib lib.c)\n"),
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := CMakeExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const CSPROJ_FILE_FORMAT = "*.csproj"
+
+var targetFrameworkRegex = regexp.MustCompile(`<TargetFramework>\s*net(\d+\.\d+)\s*</TargetFramework>`)
+
+type DotnetIsolatedExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*DotnetIsolatedExtractor) GetName() string {
+	return "dotnetisolated"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*DotnetIsolatedExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "dotnetisolated"
+}
+
+// ReadDefaults reads the target framework from the project file
+func (*DotnetIsolatedExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	files, err := r.FindFiles(".", []string{CSPROJ_FILE_FORMAT}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("error finding csproj files: %v", err)
+	}
+
+	for _, file := range files {
+		content, err := r.ReadFile(file)
+		if err != nil {
+			log.Warnf("Unable to read %s, skipping detection", file)
+			continue
+		}
+		if match := targetFrameworkRegex.FindSubmatch(content); match != nil {
+			extractedValues["VERSION"] = string(match[1])
+			extractedValues["BUILDERVERSION"] = string(match[1])
+			break
+		}
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &DotnetIsolatedExtractor{}
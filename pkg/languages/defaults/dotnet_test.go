@@ -0,0 +1,41 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestDotnetIsolatedExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract target framework",
+			files: map[string][]byte{
+				"Functions.csproj": []byte("<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n    <AzureFunctionsVersion>v4</AzureFunctionsVersion>\n  </PropertyGroup>\n</Project>\n"),
+			},
+			want: map[string]string{
+				"VERSION":        "8.0",
+				"BUILDERVERSION": "8.0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := DotnetIsolatedExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
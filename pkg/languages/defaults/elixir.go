@@ -0,0 +1,51 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const MIX_FILE = "mix.exs"
+
+var (
+	mixAppRegex           = regexp.MustCompile(`\bapp:\s*:(\w+)`)
+	mixElixirVersionRegex = regexp.MustCompile(`\belixir:\s*"[~>=\s]*(\d+\.\d+)`)
+)
+
+type ElixirExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*ElixirExtractor) GetName() string {
+	return "elixir"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*ElixirExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "elixir"
+}
+
+// ReadDefaults reads the release name and elixir version from the mix project file
+func (*ElixirExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	if !r.Exists(MIX_FILE) {
+		return extractedValues, nil
+	}
+
+	content, err := r.ReadFile(MIX_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", MIX_FILE, err)
+	}
+	if match := mixAppRegex.FindSubmatch(content); match != nil {
+		extractedValues["RELEASENAME"] = string(match[1])
+	}
+	if match := mixElixirVersionRegex.FindSubmatch(content); match != nil {
+		extractedValues["BUILDERVERSION"] = string(match[1])
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &ElixirExtractor{}
@@ -0,0 +1,46 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestElixirExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract release name and elixir version",
+			files: map[string][]byte{
+				"mix.exs": []byte("defmodule MyApp.MixProject do\n  use Mix.Project\n\n  def project do\n    [\n      app: :my_app,\n      version: \"0.1.0\",\n      elixir: \"~> 1.15\",\n    ]\n  end\nend\n"),
+			},
+			want: map[string]string{
+				"RELEASENAME":    "my_app",
+				"BUILDERVERSION": "1.15",
+			},
+		},
+		{
+			name:  "no mix file",
+			files: map[string][]byte{},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ElixirExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package defaults
+
+import (
+	"strings"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// scriptEntrypoints are the conventional entrypoints of deno and bun applications, in order of preference
+var scriptEntrypoints = []string{
+	"main.ts",
+	"mod.ts",
+	"server.ts",
+	"index.ts",
+	"src/main.ts",
+	"src/index.ts",
+	"main.js",
+	"index.js",
+}
+
+// ScriptEntrypointExtractor detects the ENTRYPOINT of deno and bun applications, preferring
+// the module or main set in package.json over the conventional file names
+type ScriptEntrypointExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*ScriptEntrypointExtractor) GetName() string {
+	return "scriptentrypoint"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*ScriptEntrypointExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "deno" || lowerlang == "bun"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*ScriptEntrypointExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+
+	pkg, err := readPackageJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, entrypoint := range []string{pkg.Module, pkg.Main} {
+		if entrypoint != "" {
+			extractedValues["ENTRYPOINT"] = strings.TrimPrefix(entrypoint, "./")
+			return extractedValues, nil
+		}
+	}
+
+	for _, entrypoint := range scriptEntrypoints {
+		if r.Exists(entrypoint) {
+			extractedValues["ENTRYPOINT"] = entrypoint
+			break
+		}
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &ScriptEntrypointExtractor{}
@@ -0,0 +1,57 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestScriptEntrypointExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "prefer package.json module",
+			files: map[string][]byte{
+				"package.json": []byte(`{"module": "./src/server.ts", "main": "index.js"}`),
+				"index.ts":     []byte(""),
+			},
+			want: map[string]string{
+				"ENTRYPOINT": "src/server.ts",
+			},
+		},
+		{
+			name: "conventional entrypoint",
+			files: map[string][]byte{
+				"deno.json": []byte("{}"),
+				"server.ts": []byte(""),
+				"mod.ts":    []byte(""),
+			},
+			want: map[string]string{
+				"ENTRYPOINT": "mod.ts",
+			},
+		},
+		{
+			name:  "no entrypoint",
+			files: map[string][]byte{},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ScriptEntrypointExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
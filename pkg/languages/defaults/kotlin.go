@@ -0,0 +1,53 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const KOTLIN_GRADLE_FILE_FORMAT = "*.gradle.kts"
+
+var jvmToolchainRegex = regexp.MustCompile(`jvmToolchain\(\s*(\d+)\s*\)`)
+
+type KotlinExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*KotlinExtractor) GetName() string {
+	return "kotlin"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*KotlinExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "kotlin"
+}
+
+// ReadDefaults reads the jvm toolchain version from the gradle kotlin build script
+func (*KotlinExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	files, err := r.FindFiles(".", []string{KOTLIN_GRADLE_FILE_FORMAT}, 2)
+	if err != nil {
+		return nil, fmt.Errorf("error finding gradle kotlin files: %v", err)
+	}
+
+	for _, file := range files {
+		content, err := r.ReadFile(file)
+		if err != nil {
+			log.Warnf("Unable to read %s, skipping detection", file)
+			continue
+		}
+		if match := jvmToolchainRegex.FindSubmatch(content); match != nil {
+			extractedValues["VERSION"] = string(match[1]) + "-jre"
+			extractedValues["BUILDERVERSION"] = "jdk" + string(match[1])
+			break
+		}
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &KotlinExtractor{}
@@ -0,0 +1,48 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestKotlinExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract jvm toolchain",
+			files: map[string][]byte{
+				"build.gradle.kts": []byte("plugins {\n    kotlin(\"jvm\") version \"1.9.23\"\n}\nkotlin {\n    jvmToolchain(17)\n}\n"),
+			},
+			want: map[string]string{
+				"VERSION":        "17-jre",
+				"BUILDERVERSION": "jdk17",
+			},
+		},
+		{
+			name: "no toolchain",
+			files: map[string][]byte{
+				"build.gradle.kts": []byte("plugins {\n    kotlin(\"jvm\") version \"1.9.23\"\n}\n"),
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := KotlinExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
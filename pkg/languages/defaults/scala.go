@@ -0,0 +1,45 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const SBT_BUILD_PROPERTIES = "project/build.properties"
+
+var sbtVersionRegex = regexp.MustCompile(`(?m)^\s*sbt\.version\s*=\s*(\S+)\s*$`)
+
+type ScalaExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*ScalaExtractor) GetName() string {
+	return "scala"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*ScalaExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "scala"
+}
+
+// ReadDefaults reads the sbt version from project/build.properties
+func (*ScalaExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	if !r.Exists(SBT_BUILD_PROPERTIES) {
+		return extractedValues, nil
+	}
+
+	content, err := r.ReadFile(SBT_BUILD_PROPERTIES)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", SBT_BUILD_PROPERTIES, err)
+	}
+	if match := sbtVersionRegex.FindSubmatch(content); match != nil {
+		extractedValues["SBTVERSION"] = string(match[1])
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &ScalaExtractor{}
@@ -0,0 +1,48 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestScalaExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract sbt version",
+			files: map[string][]byte{
+				"build.sbt":                []byte("scalaVersion := \"3.3.3\"\n"),
+				"project/build.properties": []byte("# sbt\nsbt.version = 1.10.0\n"),
+			},
+			want: map[string]string{
+				"SBTVERSION": "1.10.0",
+			},
+		},
+		{
+			name: "no build properties",
+			files: map[string][]byte{
+				"build.sbt": []byte("scalaVersion := \"3.3.3\"\n"),
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ScalaExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const ANGULAR_CONFIG_FILE = "angular.json"
+
+// staticSiteFramework describes a frontend framework that builds to static files
+type staticSiteFramework struct {
+	name        string
+	configFiles []string
+	dependency  string
+	outputDir   string
+	spa         bool
+}
+
+// staticSiteFrameworks are checked in order, so frameworks that are built on top of others come first
+var staticSiteFrameworks = []staticSiteFramework{
+	{name: "angular", configFiles: []string{ANGULAR_CONFIG_FILE}, dependency: "@angular/core", outputDir: "dist", spa: true},
+	{name: "astro", configFiles: []string{"astro.config.mjs", "astro.config.ts", "astro.config.js"}, dependency: "astro", outputDir: "dist", spa: false},
+	{name: "gatsby", configFiles: []string{"gatsby-config.js", "gatsby-config.ts"}, dependency: "gatsby", outputDir: "public", spa: false},
+	{name: "sveltekit", configFiles: []string{"svelte.config.js"}, dependency: "@sveltejs/adapter-static", outputDir: "build", spa: true},
+	{name: "vue-cli", configFiles: []string{"vue.config.js"}, dependency: "@vue/cli-service", outputDir: "dist", spa: true},
+	{name: "vite", configFiles: []string{"vite.config.js", "vite.config.ts", "vite.config.mjs", "vite.config.cjs"}, dependency: "vite", outputDir: "dist", spa: true},
+	{name: "create-react-app", dependency: "react-scripts", outputDir: "build", spa: true},
+}
+
+var angularOutputPathRegex = regexp.MustCompile(`"outputPath"\s*:\s*"([^"]+)"`)
+
+// detectStaticSiteFramework returns the framework used by the repo, matching on config files first and then dependencies
+func detectStaticSiteFramework(r reporeader.RepoReader, pkg packageJSON) (staticSiteFramework, bool) {
+	for _, framework := range staticSiteFrameworks {
+		for _, configFile := range framework.configFiles {
+			if r.Exists(configFile) {
+				return framework, true
+			}
+		}
+	}
+	for _, framework := range staticSiteFrameworks {
+		if pkg.hasDependency(framework.dependency) {
+			return framework, true
+		}
+	}
+	return staticSiteFramework{}, false
+}
+
+// IsStaticSite returns whether the repo is a node project that builds to static files which can be served
+// by nginx. A project is a static site when it has a build script and either uses a static site framework,
+// or has no start script to run a server with.
+func IsStaticSite(r reporeader.RepoReader) (bool, error) {
+	if !r.Exists(PACKAGE_JSON) {
+		return false, nil
+	}
+	pkg, err := readPackageJSON(r)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := pkg.Scripts["build"]; !ok {
+		return false, nil
+	}
+
+	if _, ok := detectStaticSiteFramework(r, pkg); ok {
+		return true, nil
+	}
+	_, hasStart := pkg.Scripts["start"]
+	return !hasStart, nil
+}
+
+type StaticSiteExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*StaticSiteExtractor) GetName() string {
+	return "staticsite"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*StaticSiteExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "staticsite"
+}
+
+// ReadDefaults reads the node version and the framework's output directory and routing mode
+func (*StaticSiteExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+
+	nodeVersion, err := readNodeVersion(r)
+	if err != nil {
+		return nil, err
+	}
+	if nodeVersion != "" {
+		extractedValues["BUILDERVERSION"] = nodeVersion
+	}
+
+	pkg, err := readPackageJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	framework, ok := detectStaticSiteFramework(r, pkg)
+	if !ok {
+		return extractedValues, nil
+	}
+	extractedValues["OUTPUTDIR"] = framework.outputDir
+	extractedValues["SPAFALLBACK"] = fmt.Sprintf("%t", framework.spa)
+
+	if framework.name == "angular" && r.Exists(ANGULAR_CONFIG_FILE) {
+		content, err := r.ReadFile(ANGULAR_CONFIG_FILE)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", ANGULAR_CONFIG_FILE, err)
+		}
+		if match := angularOutputPathRegex.FindSubmatch(content); match != nil {
+			extractedValues["OUTPUTDIR"] = strings.TrimSuffix(string(match[1]), "/")
+		}
+	}
+
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &StaticSiteExtractor{}
@@ -0,0 +1,132 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestIsStaticSite(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  bool
+	}{
+		{
+			name: "create react app",
+			files: map[string][]byte{
+				"package.json": []byte(`{"scripts": {"start": "react-scripts start", "build": "react-scripts build"}, "dependencies": {"react-scripts": "5.0.1"}}`),
+			},
+			want: true,
+		},
+		{
+			name: "build script without start",
+			files: map[string][]byte{
+				"package.json": []byte(`{"scripts": {"build": "webpack --mode production"}}`),
+			},
+			want: true,
+		},
+		{
+			name: "express server",
+			files: map[string][]byte{
+				"package.json": []byte(`{"scripts": {"build": "tsc", "start": "node dist/index.js"}, "dependencies": {"express": "4.19.2"}}`),
+			},
+			want: false,
+		},
+		{
+			name: "no build script",
+			files: map[string][]byte{
+				"package.json":   []byte(`{"scripts": {"dev": "vite"}}`),
+				"vite.config.js": []byte(""),
+			},
+			want: false,
+		},
+		{
+			name:  "no package.json",
+			files: map[string][]byte{"index.html": []byte("")},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsStaticSite(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("IsStaticSite() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("IsStaticSite() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticSiteExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "vite",
+			files: map[string][]byte{
+				".nvmrc":         []byte("20"),
+				"package.json":   []byte(`{"scripts": {"build": "vite build"}}`),
+				"vite.config.ts": []byte(""),
+			},
+			want: map[string]string{
+				"BUILDERVERSION": "20",
+				"OUTPUTDIR":      "dist",
+				"SPAFALLBACK":    "true",
+			},
+		},
+		{
+			name: "create react app",
+			files: map[string][]byte{
+				"package.json": []byte(`{"scripts": {"build": "react-scripts build"}, "dependencies": {"react-scripts": "5.0.1"}}`),
+			},
+			want: map[string]string{
+				"OUTPUTDIR":   "build",
+				"SPAFALLBACK": "true",
+			},
+		},
+		{
+			name: "gatsby",
+			files: map[string][]byte{
+				"package.json":     []byte(`{"scripts": {"build": "gatsby build"}, "engines": {"node": ">=18"}}`),
+				"gatsby-config.js": []byte(""),
+			},
+			want: map[string]string{
+				"BUILDERVERSION": "18",
+				"OUTPUTDIR":      "public",
+				"SPAFALLBACK":    "false",
+			},
+		},
+		{
+			name: "angular output path",
+			files: map[string][]byte{
+				"package.json": []byte(`{"scripts": {"build": "ng build"}}`),
+				"angular.json": []byte(`{"projects": {"app": {"architect": {"build": {"options": {"outputPath": "dist/app/"}}}}}}`),
+			},
+			want: map[string]string{
+				"OUTPUTDIR":   "dist/app",
+				"SPAFALLBACK": "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := StaticSiteExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const (
+	NVMRC_FILE    = ".nvmrc"
+	TSCONFIG_FILE = "tsconfig.json"
+	PACKAGE_JSON  = "package.json"
+)
+
+var (
+	nodeMajorVersionRegex = regexp.MustCompile(`(\d+)`)
+	tsOutDirRegex         = regexp.MustCompile(`"outDir"\s*:\s*"([^"]+)"`)
+)
+
+type packageJSON struct {
+	Main            string            `json:"main"`
+	Module          string            `json:"module"`
+	Engines         map[string]string `json:"engines"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// hasDependency returns whether the package is a dependency or dev dependency
+func (p packageJSON) hasDependency(name string) bool {
+	if _, ok := p.Dependencies[name]; ok {
+		return true
+	}
+	_, ok := p.DevDependencies[name]
+	return ok
+}
+
+type TypeScriptExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*TypeScriptExtractor) GetName() string {
+	return "typescript"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*TypeScriptExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "typescript"
+}
+
+// ReadDefaults reads the node version from .nvmrc or the package.json engines, and the
+// compiler output directory from tsconfig.json
+func (*TypeScriptExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+
+	nodeVersion, err := readNodeVersion(r)
+	if err != nil {
+		return nil, err
+	}
+	if nodeVersion != "" {
+		extractedValues["VERSION"] = nodeVersion
+	}
+
+	// tsconfig.json allows comments and trailing commas, so the outDir is matched rather than decoded
+	if r.Exists(TSCONFIG_FILE) {
+		content, err := r.ReadFile(TSCONFIG_FILE)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", TSCONFIG_FILE, err)
+		}
+		if match := tsOutDirRegex.FindSubmatch(content); match != nil {
+			extractedValues["OUTPUTDIR"] = strings.TrimSuffix(path.Clean(string(match[1])), "/")
+		}
+	}
+
+	return extractedValues, nil
+}
+
+// readNodeVersion returns the node major version from .nvmrc or the package.json engines, or an empty string
+func readNodeVersion(r reporeader.RepoReader) (string, error) {
+	if r.Exists(NVMRC_FILE) {
+		content, err := r.ReadFile(NVMRC_FILE)
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %v", NVMRC_FILE, err)
+		}
+		if match := nodeMajorVersionRegex.FindSubmatch(content); match != nil {
+			return string(match[1]), nil
+		}
+	}
+
+	pkg, err := readPackageJSON(r)
+	if err != nil {
+		return "", err
+	}
+	if match := nodeMajorVersionRegex.FindStringSubmatch(pkg.Engines["node"]); match != nil {
+		return match[1], nil
+	}
+	return "", nil
+}
+
+// readPackageJSON returns the decoded package.json, or an empty packageJSON when the repo doesn't have one
+func readPackageJSON(r reporeader.RepoReader) (packageJSON, error) {
+	pkg := packageJSON{}
+	if !r.Exists(PACKAGE_JSON) {
+		return pkg, nil
+	}
+
+	content, err := r.ReadFile(PACKAGE_JSON)
+	if err != nil {
+		return pkg, fmt.Errorf("error reading %s: %v", PACKAGE_JSON, err)
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		log.Warnf("Unable to parse %s, skipping detection: %v", PACKAGE_JSON, err)
+	}
+	return pkg, nil
+}
+
+var _ reporeader.VariableExtractor = &TypeScriptExtractor{}
@@ -0,0 +1,52 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestTypeScriptExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "extract version from nvmrc and outDir from tsconfig",
+			files: map[string][]byte{
+				".nvmrc":        []byte("v18.19.0\n"),
+				"package.json":  []byte(`{"engines": {"node": ">=20"}}`),
+				"tsconfig.json": []byte("{\n  // compiler options\n  \"compilerOptions\": {\n    \"outDir\": \"./build/\",\n  }\n}\n"),
+			},
+			want: map[string]string{
+				"VERSION":   "18",
+				"OUTPUTDIR": "build",
+			},
+		},
+		{
+			name: "extract version from package.json engines",
+			files: map[string][]byte{
+				"package.json": []byte(`{"engines": {"node": ">=20.0.0"}}`),
+			},
+			want: map[string]string{
+				"VERSION": "20",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := TypeScriptExtractor{}
+			got, err := e.ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package languages
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+const (
+	dockerignoreFileName = ".dockerignore"
+	gitignoreFileName    = ".gitignore"
+	gitignoreHeader      = "# entries from .gitignore"
+)
+
+// DockerignoreWriter is a TemplateWriter that merges the entries of the repo's .gitignore into the
+// rendered .dockerignore, and leaves an existing .dockerignore in the destination untouched.
+// All other files are passed through to the wrapped TemplateWriter.
+type DockerignoreWriter struct {
+	templatewriter.TemplateWriter
+	RepoReader reporeader.RepoReader
+}
+
+var _ templatewriter.TemplateWriter = &DockerignoreWriter{}
+
+func (w *DockerignoreWriter) WriteFile(filePath string, data []byte) error {
+	if path.Base(filePath) != dockerignoreFileName || w.RepoReader == nil {
+		return w.TemplateWriter.WriteFile(filePath, data)
+	}
+
+	if w.RepoReader.Exists(filePath) {
+		log.Infof("--> Found %s, skipping %s creation...", filePath, dockerignoreFileName)
+		return nil
+	}
+
+	gitignorePath := path.Join(path.Dir(filePath), gitignoreFileName)
+	if !w.RepoReader.Exists(gitignorePath) {
+		return w.TemplateWriter.WriteFile(filePath, data)
+	}
+
+	gitignore, err := w.RepoReader.ReadFile(gitignorePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", gitignorePath, err)
+	}
+
+	log.Debugf("merging %s into %s", gitignorePath, filePath)
+	return w.TemplateWriter.WriteFile(filePath, MergeDockerignore(data, GitignoreToDockerignore(gitignore)))
+}
+
+// GitignoreToDockerignore converts the patterns of a .gitignore file into the equivalent .dockerignore patterns.
+// Unanchored gitignore patterns match at any depth, so they are prefixed with **/ since .dockerignore
+// patterns are always relative to the root of the build context.
+func GitignoreToDockerignore(gitignore []byte) []string {
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(gitignore))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negation := ""
+		if strings.HasPrefix(line, "!") {
+			negation = "!"
+			line = strings.TrimPrefix(line, "!")
+		}
+
+		pattern := strings.TrimSuffix(line, "/")
+		switch {
+		case pattern == "":
+			continue
+		case strings.HasPrefix(pattern, "/"):
+			pattern = strings.TrimPrefix(pattern, "/")
+		case !strings.Contains(pattern, "/") && !strings.HasPrefix(pattern, "**"):
+			pattern = "**/" + pattern
+		}
+
+		patterns = append(patterns, negation+pattern)
+	}
+	return patterns
+}
+
+// MergeDockerignore appends the patterns that aren't already in the dockerignore content
+func MergeDockerignore(dockerignore []byte, patterns []string) []byte {
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(dockerignore), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	toAdd := make([]string, 0)
+	for _, pattern := range patterns {
+		if existing[pattern] || existing[pattern+"/"] {
+			continue
+		}
+		existing[pattern] = true
+		toAdd = append(toAdd, pattern)
+	}
+	if len(toAdd) == 0 {
+		return dockerignore
+	}
+
+	merged := strings.TrimRight(string(dockerignore), "\n")
+	merged += "\n\n" + gitignoreHeader + "\n" + strings.Join(toAdd, "\n") + "\n"
+	return []byte(merged)
+}
@@ -0,0 +1,90 @@
+package languages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestGitignoreToDockerignore(t *testing.T) {
+	gitignore := []byte(`# dependencies
+node_modules/
+/dist
+*.log
+
+build/output
+!important.log
+**/tmp
+`)
+	assert.Equal(t, []string{
+		"**/node_modules",
+		"dist",
+		"**/*.log",
+		"build/output",
+		"!**/important.log",
+		"**/tmp",
+	}, GitignoreToDockerignore(gitignore))
+}
+
+func TestMergeDockerignore(t *testing.T) {
+	dockerignore := []byte("Dockerfile\ncharts/\n**/node_modules\n")
+
+	merged := MergeDockerignore(dockerignore, []string{"charts", "**/node_modules", "dist", "dist"})
+	assert.Equal(t, "Dockerfile\ncharts/\n**/node_modules\n\n# entries from .gitignore\ndist\n", string(merged))
+
+	unchanged := MergeDockerignore(dockerignore, []string{"charts"})
+	assert.Equal(t, string(dockerignore), string(unchanged))
+}
+
+func TestDockerignoreWriter(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, ".")
+	inputs := map[string]string{"PORT": "8080", "VERSION": "14"}
+
+	tests := []struct {
+		name                 string
+		repoFiles            map[string][]byte
+		expectDockerignore   bool
+		expectedDockerignore []string
+	}{
+		{
+			name:               "no gitignore",
+			repoFiles:          map[string][]byte{},
+			expectDockerignore: true,
+		},
+		{
+			name:                 "merges gitignore",
+			repoFiles:            map[string][]byte{".gitignore": []byte("node_modules/\n.env\n")},
+			expectDockerignore:   true,
+			expectedDockerignore: []string{"node_modules/", "# entries from .gitignore", "**/.env"},
+		},
+		{
+			name:               "existing dockerignore",
+			repoFiles:          map[string][]byte{".dockerignore": []byte("custom")},
+			expectDockerignore: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileMapWriter := &writers.FileMapWriter{}
+			w := &DockerignoreWriter{
+				TemplateWriter: fileMapWriter,
+				RepoReader:     reporeader.FakeRepoReader{Files: tt.repoFiles},
+			}
+
+			err := l.CreateDockerfileForLanguage("javascript", inputs, w)
+			assert.Nil(t, err)
+			assert.NotNil(t, fileMapWriter.FileMap["Dockerfile"])
+
+			dockerignore, ok := fileMapWriter.FileMap[".dockerignore"]
+			assert.Equal(t, tt.expectDockerignore, ok)
+			for _, expected := range tt.expectedDockerignore {
+				assert.Contains(t, string(dockerignore), expected)
+			}
+		})
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"strings"
 
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
 	"github.com/Azure/draft/pkg/languages/defaults"
 	"github.com/Azure/draft/pkg/reporeader"
@@ -61,6 +61,69 @@ func (l *Languages) CreateDockerfileForLanguage(lang string, customInputs map[st
 	return nil
 }
 
+// UnusedVariables returns the variables of the language that none of the templates included for inputs use, such as
+// the variables of the default Dockerfile when the hardened profile is generated
+func (l *Languages) UnusedVariables(lang string, inputs map[string]string) ([]string, error) {
+	val, ok := l.langs[lang]
+	if !ok {
+		return nil, fmt.Errorf("language %s is not supported", lang)
+	}
+	draftConfig := l.configs[lang]
+	srcDir := path.Join(parentDirName, val.Name())
+
+	used := make(map[string]bool)
+	err := fs.WalkDir(l.dockerfileTemplates, srcDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || filePath == srcDir {
+			return err
+		}
+		relPath := strings.TrimPrefix(filePath, srcDir+"/")
+		if relPath == "draft.yaml" || relPath == osutil.TemplateTestDataDir || !draftConfig.IsFileIncluded(relPath, inputs) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		content, err := fs.ReadFile(l.dockerfileTemplates, filePath)
+		if err != nil {
+			return err
+		}
+		for _, name := range osutil.TemplateVariables(string(content)) {
+			used[name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range draftConfig.NameOverrides {
+		for _, name := range osutil.TemplateVariables(override.Prefix + override.Destination) {
+			used[name] = true
+		}
+	}
+	for _, condition := range draftConfig.FileConditions {
+		used[condition.Variable] = true
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if used[variableDefault.Name] {
+			for _, name := range variableDefault.References() {
+				used[name] = true
+			}
+		}
+	}
+
+	var unused []string
+	for _, variable := range draftConfig.Variables {
+		if !used[variable.Name] {
+			unused = append(unused, variable.Name)
+		}
+	}
+	return unused, nil
+}
+
 func (l *Languages) loadConfig(lang string) (*config.DraftConfig, error) {
 	val, ok := l.langs[lang]
 	if !ok {
@@ -74,8 +137,8 @@ func (l *Languages) loadConfig(lang string) (*config.DraftConfig, error) {
 	}
 
 	var draftConfig config.DraftConfig
-	if err = yaml.Unmarshal(configBytes, &draftConfig); err != nil {
-		return nil, err
+	if err = config.DecodeStrict(configBytes, &draftConfig); err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
 	}
 
 	return &draftConfig, nil
@@ -121,6 +184,14 @@ func (l *Languages) ExtractDefaults(lowerLang string, r reporeader.RepoReader) (
 	extractors := []reporeader.VariableExtractor{
 		&defaults.PythonExtractor{},
 		&defaults.GradleExtractor{},
+		&defaults.KotlinExtractor{},
+		&defaults.ScalaExtractor{},
+		&defaults.ElixirExtractor{},
+		&defaults.TypeScriptExtractor{},
+		&defaults.ScriptEntrypointExtractor{},
+		&defaults.StaticSiteExtractor{},
+		&defaults.CMakeExtractor{},
+		&defaults.DotnetIsolatedExtractor{},
 	}
 	extractedValues := make(map[string]string)
 	if r == nil {
@@ -21,3 +21,92 @@ func TestLanguagesCreateDockerfileFileMap(t *testing.T) {
 	assert.NotNil(t, templateWriter.FileMap)
 	assert.NotNil(t, templateWriter.FileMap["/test/dest/dir/Dockerfile"])
 }
+
+func TestLanguagesCreateDockerignoreNameOverride(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "/test/dest/dir")
+	for _, lang := range l.Names() {
+		inputs := map[string]string{}
+		for _, variableDefault := range l.GetConfig(lang).VariableDefaults {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+		inputs["PORT"] = "8080"
+		inputs["ENTRYPOINT"] = "app.py"
+
+		templateWriter := &writers.FileMapWriter{}
+		err := l.CreateDockerfileForLanguage(lang, inputs, templateWriter)
+		assert.Nil(t, err)
+		assert.NotNil(t, templateWriter.FileMap["/test/dest/dir/.dockerignore"], "language %s should write .dockerignore", lang)
+		assert.Nil(t, templateWriter.FileMap["/test/dest/dir/dockerignore"], "language %s should not write dockerignore", lang)
+	}
+}
+
+func TestLanguagesCreateDockerfileProfiles(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "/test/dest/dir")
+	for _, lang := range l.Names() {
+		for _, profile := range []string{"default", "hardened"} {
+			t.Run(lang+"/"+profile, func(t *testing.T) {
+				inputs := map[string]string{}
+				for _, variableDefault := range l.GetConfig(lang).VariableDefaults {
+					inputs[variableDefault.Name] = variableDefault.Value
+				}
+				inputs["PORT"] = "8080"
+				inputs["DOCKERFILE_PROFILE"] = profile
+
+				templateWriter := &writers.FileMapWriter{}
+				err := l.CreateDockerfileForLanguage(lang, inputs, templateWriter)
+				assert.Nil(t, err)
+				assert.Nil(t, templateWriter.FileMap["/test/dest/dir/Dockerfile.hardened"])
+
+				dockerfile := string(templateWriter.FileMap["/test/dest/dir/Dockerfile"])
+				assert.NotEmpty(t, dockerfile)
+				if profile == "hardened" {
+					assert.Contains(t, dockerfile, "EXPOSE 8080")
+					assert.Contains(t, dockerfile, inputs["BUILDERIMAGE"]+" AS builder")
+					assert.Contains(t, dockerfile, "FROM "+inputs["RUNTIMEIMAGE"])
+					// a numeric uid, so that Kubernetes can verify the container doesn't run as root
+					assert.Regexp(t, `(?m)^USER [1-9][0-9]*:[1-9][0-9]*$`, dockerfile)
+					assert.Contains(t, dockerfile, "HEALTHCHECK")
+				} else {
+					assert.NotContains(t, dockerfile, "HEALTHCHECK")
+				}
+			})
+		}
+	}
+}
+
+func TestUnusedVariables(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "/test/dest/dir")
+	unused, err := l.UnusedVariables("javascript", map[string]string{"DOCKERFILE_PROFILE": "hardened"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"VERSION"}, unused)
+
+	unused, err = l.UnusedVariables("javascript", map[string]string{"DOCKERFILE_PROFILE": "default"})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"BUILDERIMAGE", "RUNTIMEIMAGE"}, unused)
+
+	_, err = l.UnusedVariables("fakeLang", nil)
+	assert.NotNil(t, err)
+}
+
+func TestLanguagesCreateStaticSiteNginxConf(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "/test/dest/dir")
+	for spaFallback, tryFiles := range map[string]string{
+		"true":  "try_files $uri $uri/ /index.html;",
+		"false": "try_files $uri $uri/ =404;",
+	} {
+		inputs := map[string]string{}
+		for _, variableDefault := range l.GetConfig("staticsite").VariableDefaults {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+		inputs["SPAFALLBACK"] = spaFallback
+
+		templateWriter := &writers.FileMapWriter{}
+		err := l.CreateDockerfileForLanguage("staticsite", inputs, templateWriter)
+		assert.Nil(t, err)
+		assert.Nil(t, templateWriter.FileMap["/test/dest/dir/nginx-spa.conf"])
+
+		nginxConf := string(templateWriter.FileMap["/test/dest/dir/nginx.conf"])
+		assert.Contains(t, nginxConf, "listen 8080;")
+		assert.Contains(t, nginxConf, tryFiles)
+	}
+}
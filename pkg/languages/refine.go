@@ -0,0 +1,81 @@
+package languages
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/languages/defaults"
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const functionsWorkerPackage = "Microsoft.Azure.Functions.Worker"
+
+var (
+	denoMarkerFiles = []string{"deno.json", "deno.jsonc", "deno.lock"}
+	bunMarkerFiles  = []string{"bun.lockb", "bun.lock", "bunfig.toml"}
+)
+
+// RefineLanguage narrows a language detected by linguist down to a more specific pack using the
+// repo's files, since linguist only sees the source language. For example, a TypeScript repo with
+// a deno.json is built with the deno pack. The language is returned unchanged when nothing matches.
+func RefineLanguage(lowerLang string, r reporeader.RepoReader) string {
+	if r == nil {
+		return lowerLang
+	}
+
+	switch lowerLang {
+	case "typescript", "javascript":
+		if anyExists(r, denoMarkerFiles) {
+			log.Debugf("found deno project files, refining %s to deno", lowerLang)
+			return "deno"
+		}
+		if anyExists(r, bunMarkerFiles) {
+			log.Debugf("found bun project files, refining %s to bun", lowerLang)
+			return "bun"
+		}
+		isStaticSite, err := defaults.IsStaticSite(r)
+		if err != nil {
+			log.Debugf("error checking for a static site: %v", err)
+		} else if isStaticSite {
+			log.Debugf("found a static site build, refining %s to staticsite", lowerLang)
+			return "staticsite"
+		}
+	case "csharp":
+		if referencesPackage(r, "*.csproj", functionsWorkerPackage) {
+			log.Debugf("found %s reference, refining %s to dotnetisolated", functionsWorkerPackage, lowerLang)
+			return "dotnetisolated"
+		}
+	}
+
+	return lowerLang
+}
+
+func anyExists(r reporeader.RepoReader, files []string) bool {
+	for _, file := range files {
+		if r.Exists(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesPackage returns whether any project file matching pattern mentions the package
+func referencesPackage(r reporeader.RepoReader, pattern, pkg string) bool {
+	files, err := r.FindFiles(".", []string{pattern}, 1)
+	if err != nil {
+		log.Debugf("error finding %s files: %v", pattern, err)
+		return false
+	}
+	for _, file := range files {
+		content, err := r.ReadFile(file)
+		if err != nil {
+			log.Debugf("error reading %s: %v", file, err)
+			continue
+		}
+		if strings.Contains(string(content), pkg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package languages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestRefineLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		files map[string][]byte
+		want  string
+	}{
+		{
+			name:  "typescript with deno.json",
+			lang:  "typescript",
+			files: map[string][]byte{"deno.json": []byte("{}"), "main.ts": []byte("")},
+			want:  "deno",
+		},
+		{
+			name:  "javascript with bun lockfile",
+			lang:  "javascript",
+			files: map[string][]byte{"bun.lockb": []byte(""), "package.json": []byte("{}")},
+			want:  "bun",
+		},
+		{
+			name: "vite site",
+			lang: "typescript",
+			files: map[string][]byte{
+				"package.json":   []byte(`{"scripts": {"dev": "vite", "build": "vite build"}}`),
+				"vite.config.ts": []byte(""),
+			},
+			want: "staticsite",
+		},
+		{
+			name:  "javascript with only a build script",
+			lang:  "javascript",
+			files: map[string][]byte{"package.json": []byte(`{"scripts": {"build": "webpack"}}`)},
+			want:  "staticsite",
+		},
+		{
+			name:  "javascript server",
+			lang:  "javascript",
+			files: map[string][]byte{"package.json": []byte(`{"scripts": {"build": "tsc", "start": "node server.js"}}`)},
+			want:  "javascript",
+		},
+		{
+			name:  "plain typescript",
+			lang:  "typescript",
+			files: map[string][]byte{"package.json": []byte("{}"), "tsconfig.json": []byte("{}")},
+			want:  "typescript",
+		},
+		{
+			name: "csharp isolated functions",
+			lang: "csharp",
+			files: map[string][]byte{
+				"Functions.csproj": []byte(`<PackageReference Include="Microsoft.Azure.Functions.Worker" Version="1.21.0" />`),
+			},
+			want: "dotnetisolated",
+		},
+		{
+			name: "csharp web app",
+			lang: "csharp",
+			files: map[string][]byte{
+				"Web.csproj": []byte(`<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`),
+			},
+			want: "csharp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RefineLanguage(tt.lang, reporeader.FakeRepoReader{Files: tt.files}))
+		})
+	}
+}
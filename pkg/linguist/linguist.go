@@ -301,6 +301,10 @@ func Alias(lang *Language) *Language {
 		"maven pom": "Java",
 		"c#":        "csharp",
 		"go module": "gomodule",
+		"c":         "cpp",
+		"c++":       "cpp",
+		"cmake":     "cpp",
+		"tsx":       "typescript",
 	}
 
 	if alias, ok := packAliases[strings.ToLower(lang.Language)]; ok {
@@ -77,6 +77,9 @@ func TestGetAlias(t *testing.T) {
 		"mAvEn POM": "Java",
 		"c#":        "csharp",
 		"Python":    "Python",
+		"C++":       "cpp",
+		"CMake":     "cpp",
+		"TSX":       "typescript",
 	}
 
 	for packName, expectedAlias := range testcases {
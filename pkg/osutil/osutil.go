@@ -19,6 +19,14 @@ import (
 // A draft variable is defined as a string of non-whitespace characters wrapped in double curly braces.
 var draftVariableRegex = regexp.MustCompile("{{[^\\s.]+\\S*}}")
 
+// TemplateTestDataDir is the directory of a template holding the example values and golden files used by
+// draft template test. It isn't rendered with the rest of the template.
+const TemplateTestDataDir = "testdata"
+
+// draftVariableNameRegex matches a single draft variable, unlike draftVariableRegex which matches neighbouring
+// variables such as {{IMAGENAME}}:{{IMAGETAG}} as one
+var draftVariableNameRegex = regexp.MustCompile(`{{([^\s.{}][^\s{}]*)}}`)
+
 // Exists returns whether the given file or directory exists or not.
 func Exists(path string) (bool, error) {
 	_, err := os.Stat(path)
@@ -78,12 +86,24 @@ func EnsureFile(file string) error {
 	return nil
 }
 
+// CopyDir renders the templates in the src directory of fileSys into dest, substituting the draft
+// variables in customInputs. Name overrides and file conditions from config are applied to every
+// file and directory, and destination paths may contain draft variables as well.
 func CopyDir(
 	fileSys fs.FS,
 	src, dest string,
 	config *config.DraftConfig,
 	customInputs map[string]string,
 	templateWriter templatewriter.TemplateWriter) error {
+	return copyDir(fileSys, src, src, dest, dest, config, customInputs, templateWriter)
+}
+
+func copyDir(
+	fileSys fs.FS,
+	srcRoot, src, destRoot, dest string,
+	config *config.DraftConfig,
+	customInputs map[string]string,
+	templateWriter templatewriter.TemplateWriter) error {
 	files, err := fs.ReadDir(fileSys, src)
 	if err != nil {
 		return err
@@ -91,19 +111,27 @@ func CopyDir(
 
 	for _, f := range files {
 
-		if f.Name() == "draft.yaml" {
+		if f.Name() == "draft.yaml" || (src == srcRoot && f.Name() == TemplateTestDataDir) {
 			continue
 		}
 
 		srcPath := path.Join(src, f.Name())
-		destPath := path.Join(dest, f.Name())
+		relPath := strings.TrimPrefix(strings.TrimPrefix(srcPath, srcRoot), "/")
+		if config != nil && !config.IsFileIncluded(relPath, customInputs) {
+			continue
+		}
+
+		destPath, err := getDestPath(relPath, f.Name(), destRoot, dest, config, customInputs)
+		if err != nil {
+			return fmt.Errorf("error resolving destination for %s: %w", srcPath, err)
+		}
 		log.Debugf("Source path: %s Dest path: %s", srcPath, destPath)
 
 		if f.IsDir() {
 			if err = templateWriter.EnsureDirectory(destPath); err != nil {
 				return err
 			}
-			if err = CopyDir(fileSys, srcPath, destPath, config, customInputs, templateWriter); err != nil {
+			if err = copyDir(fileSys, srcRoot, srcPath, destRoot, destPath, config, customInputs, templateWriter); err != nil {
 				return err
 			}
 		} else {
@@ -116,6 +144,10 @@ func CopyDir(
 				return fmt.Errorf("error substituting file %s: %w", srcPath, err)
 			}
 
+			if err = templateWriter.EnsureDirectory(path.Dir(destPath)); err != nil {
+				return err
+			}
+
 			if err = templateWriter.WriteFile(destPath, fileContent); err != nil {
 				return err
 			}
@@ -124,6 +156,24 @@ func CopyDir(
 	return nil
 }
 
+// getDestPath resolves the output path for the template at relPath, applying any name override
+// and substituting draft variables in the resulting path.
+func getDestPath(relPath, fileName, destRoot, dest string, config *config.DraftConfig, customInputs map[string]string) (string, error) {
+	destPath := path.Join(dest, checkNameOverrides(fileName, relPath, config))
+	if config != nil {
+		if override, ok := config.GetNameOverride(relPath); ok && override.Destination != "" {
+			log.Debugf("overriding destination of %s with %s", relPath, override.Destination)
+			destPath = path.Join(destRoot, override.Destination)
+		}
+	}
+
+	destPath = ReplaceVariables(destPath, customInputs)
+	if err := checkAllVariablesSubstituted(destPath); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
 /*
 	checkAllVariablesSubstituted checks that all draft variables have been substituted.
 
@@ -145,22 +195,39 @@ func replaceTemplateVariables(fileSys fs.FS, srcPath string, customInputs map[st
 		return nil, err
 	}
 
-	fileString := string(file)
+	return []byte(ReplaceVariables(string(file), customInputs)), nil
+}
 
+// TemplateVariables returns the names of the draft variables used in s, in order of first use
+func TemplateVariables(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range draftVariableNameRegex.FindAllStringSubmatch(s, -1) {
+		name := match[1]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ReplaceVariables substitutes the draft variables in customInputs into s
+func ReplaceVariables(s string, customInputs map[string]string) string {
 	for oldString, newString := range customInputs {
 		log.Debugf("replacing %s with %s", oldString, newString)
-		fileString = strings.ReplaceAll(fileString, "{{"+oldString+"}}", newString)
+		s = strings.ReplaceAll(s, "{{"+oldString+"}}", newString)
 	}
-
-	return []byte(fileString), nil
+	return s
 }
 
-func checkNameOverrides(fileName, srcPath, destPath string, config *config.DraftConfig) string {
+// checkNameOverrides returns the file name with the prefix of any name override for relPath applied
+func checkNameOverrides(fileName, relPath string, config *config.DraftConfig) string {
 	if config != nil {
-		log.Debugf("checking name override for srcPath: %s, destPath: %s", srcPath, destPath)
-		if prefix := config.GetNameOverride(fileName); prefix != "" {
-			log.Debugf("overriding file: %s with prefix: %s", destPath, prefix)
-			fileName = fmt.Sprintf("%s%s", prefix, fileName)
+		log.Debugf("checking name override for path: %s", relPath)
+		if override, ok := config.GetNameOverride(relPath); ok && override.Prefix != "" {
+			log.Debugf("overriding file: %s with prefix: %s", fileName, override.Prefix)
+			fileName = fmt.Sprintf("%s%s", override.Prefix, fileName)
 		}
 	}
 	return fileName
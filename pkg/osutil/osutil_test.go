@@ -116,3 +116,21 @@ func TestAllVariablesSubstituted(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateVariables(t *testing.T) {
+	tests := []struct {
+		String   string
+		Expected []string
+	}{
+		{"image: {{IMAGE}}:{{TAG}}", []string{"IMAGE", "TAG"}},
+		{"{{PORT}} {{ APP }} {{PORT}}", []string{"PORT"}},
+		{"{{.Values.image}} {{ .Release.Name }}", nil},
+		{"{{lower_case}}", []string{"lower_case"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.String, func(t *testing.T) {
+			assert.Equal(t, test.Expected, TemplateVariables(test.String))
+		})
+	}
+}
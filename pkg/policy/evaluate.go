@@ -0,0 +1,292 @@
+// Package policy checks generated manifests and Dockerfiles against a set of rules, offline.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/dockerfile"
+	"github.com/Azure/draft/pkg/k8svalidation"
+)
+
+const overlaysDir = "overlays"
+
+// Input is what the rules are evaluated against
+type Input struct {
+	// Manifests are multi-document yaml manifests keyed by their source, as returned by k8svalidation.RenderManifests
+	Manifests map[string][]byte
+	// Dockerfiles are Dockerfile contents keyed by path
+	Dockerfiles map[string][]byte
+}
+
+// Evaluate returns the violations of the rules in input
+func Evaluate(rules []Rule, input Input) ([]Violation, error) {
+	var violations []Violation
+
+	for _, source := range sortedKeys(input.Manifests) {
+		nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(input.Manifests[source]), OmitReaderAnnotations: true}).Read()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", source, err)
+		}
+		for _, node := range nodes {
+			for _, rule := range rules {
+				if rule.Target != TargetManifest || !rule.matchesResource(source, node) {
+					continue
+				}
+				violations = append(violations, rule.evaluateResource(source, node)...)
+			}
+		}
+	}
+
+	for _, source := range sortedKeys(input.Dockerfiles) {
+		parsed := dockerfile.Parse(input.Dockerfiles[source])
+		for _, rule := range rules {
+			if rule.Target == TargetDockerfile {
+				violations = append(violations, rule.evaluateDockerfile(source, parsed)...)
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+func (r *Rule) matchesResource(source string, node *yaml.RNode) bool {
+	if len(r.Match.Kinds) > 0 && !contains(r.Match.Kinds, node.GetKind()) {
+		return false
+	}
+	if len(r.Match.Environments) == 0 {
+		return true
+	}
+
+	environment := resourceEnvironment(source, node)
+	if environment == "" {
+		return false
+	}
+	for _, pattern := range r.Match.Environments {
+		if matched, _ := path.Match(pattern, environment); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceEnvironment is the kustomize overlay the resource was built from, or its namespace
+func resourceEnvironment(source string, node *yaml.RNode) string {
+	if path.Base(path.Dir(source)) == overlaysDir {
+		return path.Base(source)
+	}
+	return node.GetNamespace()
+}
+
+func (r *Rule) evaluateResource(source string, node *yaml.RNode) []Violation {
+	resource := node.GetKind()
+	if name := node.GetName(); name != "" {
+		resource = fmt.Sprintf("%s %s", resource, name)
+	}
+	newViolation := func(field, detail string) Violation {
+		return Violation{
+			Rule:     r.ID,
+			Severity: r.Severity,
+			Message:  r.message(detail),
+			Source:   source,
+			Resource: resource,
+			Field:    field,
+		}
+	}
+
+	var violations []Violation
+	for _, fieldPath := range r.Paths {
+		for _, field := range lookup(node, fieldPath) {
+			for _, required := range r.Require {
+				if len(lookup(field.node, required)) == 0 {
+					violations = append(violations, newViolation(joinPath(field.path, required), fmt.Sprintf("%s is required", required)))
+				}
+			}
+
+			if field.node.YNode().Kind != yaml.ScalarNode {
+				continue
+			}
+			value := field.node.YNode().Value
+			if r.Deny != nil && r.Deny.matches(value) {
+				violations = append(violations, newViolation(field.path, fmt.Sprintf("value %q is not allowed", value)))
+			}
+			if r.Allow != nil && !r.Allow.matches(value) {
+				violations = append(violations, newViolation(field.path, fmt.Sprintf("value %q is not allowed", value)))
+			}
+		}
+	}
+	return violations
+}
+
+func (r *Rule) evaluateDockerfile(source string, parsed *dockerfile.Dockerfile) []Violation {
+	instructions := parsed.Instructions
+	if r.Stage == StageFinal {
+		instructions = parsed.FinalStage()
+	}
+
+	var violations []Violation
+	found := false
+	for _, instruction := range instructions {
+		if instruction.Command != r.Instruction {
+			continue
+		}
+		found = true
+
+		denied := r.Deny != nil && r.Deny.matches(instruction.Args)
+		notAllowed := r.Allow != nil && !r.Allow.matches(instruction.Args)
+		if denied || notAllowed {
+			violations = append(violations, Violation{
+				Rule:     r.ID,
+				Severity: r.Severity,
+				Message:  r.message(fmt.Sprintf("%s %s is not allowed", instruction.Command, instruction.Args)),
+				Source:   source,
+				Line:     instruction.Line,
+			})
+		}
+	}
+
+	if r.Required && !found {
+		detail := fmt.Sprintf("a %s instruction is required", r.Instruction)
+		if r.Stage == StageFinal {
+			detail += " in the final stage"
+		}
+		violations = append(violations, Violation{
+			Rule:     r.ID,
+			Severity: r.Severity,
+			Message:  r.message(detail),
+			Source:   source,
+		})
+	}
+	return violations
+}
+
+func (r *Rule) message(detail string) string {
+	if r.Message == "" {
+		return detail
+	}
+	return fmt.Sprintf("%s: %s", detail, r.Message)
+}
+
+type field struct {
+	path string
+	node *yaml.RNode
+}
+
+// lookup returns the fields of node matching fieldPath, such as spec.containers[*].image, with their concrete paths
+func lookup(node *yaml.RNode, fieldPath string) []field {
+	fields := []field{{node: node}}
+	for _, segment := range splitPath(fieldPath) {
+		var next []field
+		for _, current := range fields {
+			next = append(next, lookupSegment(current, segment)...)
+		}
+		fields = next
+	}
+	return fields
+}
+
+func lookupSegment(current field, segment string) []field {
+	if !strings.HasPrefix(segment, "[") {
+		child := current.node.Field(segment)
+		if child == nil || child.Value == nil || child.Value.IsNil() {
+			return nil
+		}
+		return []field{{path: joinPath(current.path, segment), node: child.Value}}
+	}
+
+	if current.node.YNode().Kind != yaml.SequenceNode {
+		return nil
+	}
+	elements, err := current.node.Elements()
+	if err != nil {
+		return nil
+	}
+
+	index := strings.TrimSuffix(strings.TrimPrefix(segment, "["), "]")
+	var fields []field
+	for i, element := range elements {
+		if index == "*" || index == strconv.Itoa(i) {
+			fields = append(fields, field{path: fmt.Sprintf("%s[%d]", current.path, i), node: element})
+		}
+	}
+	return fields
+}
+
+// splitPath splits spec.containers[*].image into spec, containers, [*] and image
+func splitPath(fieldPath string) []string {
+	var segments []string
+	for _, part := range strings.Split(fieldPath, ".") {
+		for part != "" {
+			bracket := strings.Index(part, "[")
+			switch {
+			case bracket < 0:
+				segments = append(segments, part)
+				part = ""
+			case bracket > 0:
+				segments = append(segments, part[:bracket])
+				part = part[bracket:]
+			default:
+				end := strings.Index(part, "]")
+				if end < 0 {
+					end = len(part) - 1
+				}
+				segments = append(segments, part[:end+1])
+				part = part[end+1:]
+			}
+		}
+	}
+	return segments
+}
+
+func joinPath(parent, child string) string {
+	if parent == "" || strings.HasPrefix(child, "[") {
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewInput splits project files, keyed by their path relative to the project, into Dockerfiles and the
+// manifests rendered from the remaining files
+func NewInput(files map[string][]byte) (Input, error) {
+	input := Input{Dockerfiles: make(map[string][]byte)}
+	deploymentFiles := make(map[string][]byte)
+	for filePath, content := range files {
+		if dockerfile.IsDockerfile(path.Base(filePath)) {
+			input.Dockerfiles[filePath] = content
+		} else {
+			deploymentFiles[filePath] = content
+		}
+	}
+
+	manifests, err := k8svalidation.RenderManifests(deploymentFiles)
+	if err != nil {
+		return Input{}, err
+	}
+	input.Manifests = manifests
+	return input, nil
+}
@@ -0,0 +1,216 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: testapp
+  namespace: testapp-ns
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: testapp
+          image: testapp:v1
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 256Mi
+          livenessProbe:
+            tcpSocket:
+              port: 80
+          readinessProbe:
+            tcpSocket:
+              port: 80
+        - name: sidecar
+          image: sidecar:latest
+          securityContext:
+            privileged: true
+`
+
+const testService = `apiVersion: v1
+kind: Service
+metadata:
+  name: testapp
+spec:
+  type: LoadBalancer
+`
+
+func TestBuiltinRules(t *testing.T) {
+	rules, err := BuiltinRules()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, rules)
+
+	ids := make(map[string]bool)
+	for _, rule := range rules {
+		assert.False(t, ids[rule.ID], "duplicate rule id %s", rule.ID)
+		ids[rule.ID] = true
+		assert.NotEmpty(t, rule.Description, rule.ID)
+	}
+}
+
+func TestEvaluateManifests(t *testing.T) {
+	rules, err := BuiltinRules()
+	assert.Nil(t, err)
+
+	violations, err := Evaluate(rules, Input{Manifests: map[string][]byte{
+		"manifests/deployment.yaml": []byte(testDeployment),
+		"overlays/dev":              []byte(testService),
+		"overlays/production":       []byte(testService),
+	}})
+	assert.Nil(t, err)
+
+	type found struct{ rule, source, field string }
+	var got []found
+	for _, violation := range violations {
+		got = append(got, found{violation.Rule, violation.Source, violation.Field})
+	}
+	assert.ElementsMatch(t, []found{
+		{"no-host-network", "manifests/deployment.yaml", "spec.template.spec.hostNetwork"},
+		{"no-latest-image-tag", "manifests/deployment.yaml", "spec.template.spec.containers[1].image"},
+		{"no-privileged-containers", "manifests/deployment.yaml", "spec.template.spec.containers[1].securityContext.privileged"},
+		{"require-resources", "manifests/deployment.yaml", "spec.template.spec.containers[1].resources.requests.cpu"},
+		{"require-resources", "manifests/deployment.yaml", "spec.template.spec.containers[1].resources.requests.memory"},
+		{"require-resources", "manifests/deployment.yaml", "spec.template.spec.containers[1].resources.limits.memory"},
+		{"require-probes", "manifests/deployment.yaml", "spec.template.spec.containers[1].livenessProbe"},
+		{"require-probes", "manifests/deployment.yaml", "spec.template.spec.containers[1].readinessProbe"},
+		{"no-loadbalancer-in-production", "overlays/production", "spec.type"},
+	}, got)
+}
+
+const testDockerfileRules = `rules:
+  - id: dockerfile-no-latest-base
+    severity: warning
+    target: dockerfile
+    instruction: from
+    deny:
+      pattern: ':latest(\s|$)'
+  - id: dockerfile-non-root-user
+    severity: warning
+    target: dockerfile
+    instruction: USER
+    stage: final
+    required: true
+    deny:
+      pattern: '^(root|0)(:\S*)?$'
+`
+
+func TestEvaluateDockerfile(t *testing.T) {
+	policyDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(policyDir, "dockerfile.yaml"), []byte(testDockerfileRules), 0644))
+	rules, err := LoadPolicy(policyDir, false)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		dockerfile string
+		want       []string
+	}{
+		{
+			name:       "pinned non-root",
+			dockerfile: "FROM golang:1.20 AS build\nRUN go build\nFROM alpine:3.18\nUSER 1000\n",
+		},
+		{
+			name:       "latest base and root user",
+			dockerfile: "FROM golang:latest\nUSER root\n",
+			want:       []string{"dockerfile-no-latest-base", "dockerfile-non-root-user"},
+		},
+		{
+			name:       "user only in build stage",
+			dockerfile: "FROM golang:1.20 AS build\nUSER 1000\nFROM alpine:3.18\n",
+			want:       []string{"dockerfile-non-root-user"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := Evaluate(rules, Input{Dockerfiles: map[string][]byte{"Dockerfile": []byte(tt.dockerfile)}})
+			assert.Nil(t, err)
+
+			var got []string
+			for _, violation := range violations {
+				got = append(got, violation.Rule)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	policyDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(policyDir, "rules.yaml"), []byte(`rules:
+  - id: require-probes
+    disabled: true
+  - id: no-latest-image-tag
+    severity: error
+    paths: ['spec.template.spec.containers[*].image']
+    deny:
+      pattern: ':latest$'
+  - id: require-team-label
+    severity: info
+    paths: [metadata.labels]
+    require: [team]
+`), 0644))
+
+	rules, err := LoadPolicy(policyDir, true)
+	assert.Nil(t, err)
+
+	rulesByID := make(map[string]Rule)
+	for _, rule := range rules {
+		rulesByID[rule.ID] = rule
+	}
+	assert.NotContains(t, rulesByID, "require-probes")
+	assert.Contains(t, rulesByID, "require-resources")
+	assert.Equal(t, SeverityError, rulesByID["no-latest-image-tag"].Severity)
+	assert.Equal(t, TargetManifest, rulesByID["require-team-label"].Target)
+
+	userRules, err := LoadPolicy(policyDir, false)
+	assert.Nil(t, err)
+	assert.Len(t, userRules, 2)
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "missing id", rules: "rules:\n  - paths: [spec]\n    require: [replicas]\n"},
+		{name: "invalid severity", rules: "rules:\n  - id: a\n    severity: fatal\n    paths: [spec]\n    require: [replicas]\n"},
+		{name: "no check", rules: "rules:\n  - id: a\n    paths: [spec]\n"},
+		{name: "invalid pattern", rules: "rules:\n  - id: a\n    paths: [spec]\n    deny:\n      pattern: '('\n"},
+		{name: "dockerfile without instruction", rules: "rules:\n  - id: a\n    target: dockerfile\n    required: true\n"},
+		{name: "unknown target", rules: "rules:\n  - id: a\n    target: chart\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyDir := t.TempDir()
+			assert.Nil(t, os.WriteFile(filepath.Join(policyDir, "rules.yml"), []byte(tt.rules), 0644))
+			_, err := LoadPolicy(policyDir, false)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"spec", "containers", "[*]", "image"}, splitPath("spec.containers[*].image"))
+	assert.Equal(t, []string{"spec", "ports", "[0]", "[1]"}, splitPath("spec.ports[0][1]"))
+	assert.Equal(t, []string{"metadata"}, splitPath("metadata"))
+}
+
+func TestSeverity(t *testing.T) {
+	_, err := ParseSeverity("critical")
+	assert.NotNil(t, err)
+	assert.True(t, SeverityError.AtLeast(SeverityWarning))
+	assert.False(t, SeverityInfo.AtLeast(SeverityWarning))
+	assert.True(t, AnyAtLeast([]Violation{{Severity: SeverityWarning}}, SeverityWarning))
+	assert.Equal(t, Summary{Errors: 1, Warnings: 2}, Summarize([]Violation{{Severity: SeverityError}, {Severity: SeverityWarning}, {Severity: SeverityWarning}}))
+}
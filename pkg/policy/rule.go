@@ -0,0 +1,216 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/template"
+)
+
+const (
+	// TargetManifest rules check the rendered Kubernetes resources
+	TargetManifest = "manifest"
+	// TargetDockerfile rules check the instructions of the Dockerfile
+	TargetDockerfile = "dockerfile"
+
+	// StageFinal limits a Dockerfile rule to the instructions of the last build stage
+	StageFinal = "final"
+
+	builtinRulesDir = "policies"
+)
+
+// RuleSet is the content of a policy file
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a policy check on the rendered manifests or the Dockerfile.
+//
+// Manifest rules select the resources of Match.Kinds, optionally only in the environments of Match.Environments,
+// then every field matching one of Paths. Paths are dot separated and may index lists with [*] or [n], as in
+// spec.template.spec.containers[*].image. A violation is reported for each selected field missing one of the
+// Require paths, or whose value is rejected by Deny or Allow.
+//
+// Dockerfile rules select the Instruction, optionally only in the final Stage, and check its arguments with Deny
+// and Allow. Required reports a violation when the instruction is missing.
+type Rule struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Severity    Severity `yaml:"severity"`
+	Target      string   `yaml:"target"`
+	// Message explains how to fix a violation of the rule
+	Message string `yaml:"message"`
+	// Disabled turns off a rule, such as a built-in rule overridden by a user rule with the same id
+	Disabled bool `yaml:"disabled"`
+
+	Match   Match    `yaml:"match"`
+	Paths   []string `yaml:"paths"`
+	Require []string `yaml:"require"`
+
+	Instruction string `yaml:"instruction"`
+	Stage       string `yaml:"stage"`
+	Required    bool   `yaml:"required"`
+
+	Deny  *ValueCondition `yaml:"deny"`
+	Allow *ValueCondition `yaml:"allow"`
+}
+
+// Match selects the resources a manifest rule applies to
+type Match struct {
+	Kinds []string `yaml:"kinds"`
+	// Environments are glob patterns matched against the kustomize overlay a resource was built from,
+	// or the resource's namespace otherwise
+	Environments []string `yaml:"environments"`
+}
+
+// ValueCondition matches a value that is one of Values or matches the regular expression Pattern
+type ValueCondition struct {
+	Values  []string `yaml:"values"`
+	Pattern string   `yaml:"pattern"`
+
+	regex *regexp.Regexp
+}
+
+func (c *ValueCondition) matches(value string) bool {
+	for _, v := range c.Values {
+		if v == value {
+			return true
+		}
+	}
+	return c.regex != nil && c.regex.MatchString(value)
+}
+
+func (c *ValueCondition) compile() error {
+	if c == nil || c.Pattern == "" {
+		return nil
+	}
+	regex, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", c.Pattern, err)
+	}
+	c.regex = regex
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if r.ID == "" {
+		return errors.New("rule is missing an id")
+	}
+	if r.Severity == "" {
+		r.Severity = SeverityWarning
+	}
+	if _, err := ParseSeverity(string(r.Severity)); err != nil {
+		return fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+	if r.Disabled {
+		return nil
+	}
+
+	if err := r.Deny.compile(); err != nil {
+		return fmt.Errorf("rule %s: deny: %w", r.ID, err)
+	}
+	if err := r.Allow.compile(); err != nil {
+		return fmt.Errorf("rule %s: allow: %w", r.ID, err)
+	}
+
+	switch r.Target {
+	case "", TargetManifest:
+		r.Target = TargetManifest
+		if len(r.Paths) == 0 {
+			return fmt.Errorf("rule %s: manifest rules need at least one path", r.ID)
+		}
+		if len(r.Require) == 0 && r.Deny == nil && r.Allow == nil {
+			return fmt.Errorf("rule %s: manifest rules need require, deny or allow", r.ID)
+		}
+	case TargetDockerfile:
+		if r.Instruction == "" {
+			return fmt.Errorf("rule %s: dockerfile rules need an instruction", r.ID)
+		}
+		r.Instruction = strings.ToUpper(r.Instruction)
+		if !r.Required && r.Deny == nil && r.Allow == nil {
+			return fmt.Errorf("rule %s: dockerfile rules need required, deny or allow", r.ID)
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown target %s, expected %s or %s", r.ID, r.Target, TargetManifest, TargetDockerfile)
+	}
+	return nil
+}
+
+// LoadRules reads the rules of every yaml file in dir
+func LoadRules(fileSys fs.FS, dir string) ([]Rule, error) {
+	entries, err := fs.ReadDir(fileSys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	for _, entry := range entries {
+		if entry.IsDir() || (path.Ext(entry.Name()) != ".yaml" && path.Ext(entry.Name()) != ".yml") {
+			continue
+		}
+		filePath := path.Join(dir, entry.Name())
+		content, err := fs.ReadFile(fileSys, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var ruleSet RuleSet
+		if err = yaml.Unmarshal(content, &ruleSet); err != nil {
+			return nil, fmt.Errorf("parsing policy file %s: %w", filePath, err)
+		}
+		for i := range ruleSet.Rules {
+			if err = ruleSet.Rules[i].validate(); err != nil {
+				return nil, fmt.Errorf("policy file %s: %w", filePath, err)
+			}
+		}
+		rules = append(rules, ruleSet.Rules...)
+	}
+	return rules, nil
+}
+
+// BuiltinRules returns the rules shipped with draft
+func BuiltinRules() ([]Rule, error) {
+	return LoadRules(template.Policies, builtinRulesDir)
+}
+
+// LoadPolicy returns the built-in rules together with the user rules in policyDir, if set. A user rule replaces
+// the built-in rule with the same id, and disabled rules are dropped.
+func LoadPolicy(policyDir string, includeBuiltin bool) ([]Rule, error) {
+	rulesByID := make(map[string]Rule)
+	if includeBuiltin {
+		builtinRules, err := BuiltinRules()
+		if err != nil {
+			return nil, fmt.Errorf("loading built-in policy rules: %w", err)
+		}
+		for _, rule := range builtinRules {
+			rulesByID[rule.ID] = rule
+		}
+	}
+
+	if policyDir != "" {
+		userRules, err := LoadRules(os.DirFS(policyDir), ".")
+		if err != nil {
+			return nil, fmt.Errorf("loading policy rules from %s: %w", policyDir, err)
+		}
+		for _, rule := range userRules {
+			rulesByID[rule.ID] = rule
+		}
+	}
+
+	rules := make([]Rule, 0, len(rulesByID))
+	for _, rule := range rulesByID {
+		if !rule.Disabled {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
@@ -0,0 +1,32 @@
+package policy
+
+import "fmt"
+
+// Severity is how serious a policy violation is
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var severityLevels = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityWarning: 1,
+	SeverityError:   2,
+}
+
+// ParseSeverity returns the severity named s
+func ParseSeverity(s string) (Severity, error) {
+	severity := Severity(s)
+	if _, ok := severityLevels[severity]; !ok {
+		return "", fmt.Errorf("invalid severity %q, expected one of info, warning, error", s)
+	}
+	return severity, nil
+}
+
+// AtLeast returns whether the severity is as serious as other or more
+func (s Severity) AtLeast(other Severity) bool {
+	return severityLevels[s] >= severityLevels[other]
+}
@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation is a failed policy check
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Source is the Dockerfile, or the file, chart or kustomization the resource was rendered from
+	Source string `json:"source"`
+	// Resource is the kind and name of the resource, empty for Dockerfile violations
+	Resource string `json:"resource,omitempty"`
+	// Field is the path of the offending field, such as spec.template.spec.containers[0].image
+	Field string `json:"field,omitempty"`
+	// Line is the Dockerfile line of the offending instruction
+	Line int `json:"line,omitempty"`
+}
+
+func (v Violation) String() string {
+	location := []string{v.Source}
+	if v.Line > 0 {
+		location = []string{fmt.Sprintf("%s:%d", v.Source, v.Line)}
+	}
+	if v.Resource != "" {
+		location = append(location, v.Resource)
+	}
+	if v.Field != "" {
+		location = append(location, v.Field)
+	}
+	return fmt.Sprintf("[%s] %s: %s: %s", v.Severity, v.Rule, strings.Join(location, ": "), v.Message)
+}
+
+// Summary counts violations by severity
+type Summary struct {
+	Errors   int `json:"error"`
+	Warnings int `json:"warning"`
+	Info     int `json:"info"`
+}
+
+// Summarize counts the violations by severity
+func Summarize(violations []Violation) Summary {
+	var summary Summary
+	for _, violation := range violations {
+		switch violation.Severity {
+		case SeverityError:
+			summary.Errors++
+		case SeverityWarning:
+			summary.Warnings++
+		default:
+			summary.Info++
+		}
+	}
+	return summary
+}
+
+// AnyAtLeast returns whether any violation is at least as serious as threshold
+func AnyAtLeast(violations []Violation, threshold Severity) bool {
+	for _, violation := range violations {
+		if violation.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,11 +3,15 @@ package prompts
 import (
 	"fmt"
 	"io"
+	"path"
+	"slices"
 
 	"github.com/manifoldco/promptui"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 
 	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/filematches"
 )
 
 func RunPromptsFromConfig(config *config.DraftConfig) (map[string]string, error) {
@@ -18,18 +22,30 @@ func RunPromptsFromConfigWithSkips(config *config.DraftConfig, varsToSkip []stri
 	return RunPromptsFromConfigWithSkipsIO(config, varsToSkip, nil, nil)
 }
 
+// RunPromptsFromConfigWithInputs runs the prompts for the given config, using the values of knownInputs instead of
+// prompting for the variables they set. The known values are returned with the prompted ones, and are available
+// to the defaults of the other variables.
+func RunPromptsFromConfigWithInputs(config *config.DraftConfig, knownInputs map[string]string) (map[string]string, error) {
+	return runPrompts(config, maps.Keys(knownInputs), knownInputs, nil, nil)
+}
+
 // RunPromptsFromConfigWithSkipsIO runs the prompts for the given config
 // skipping any variables in varsToSkip or where the BuilderVar.IsPromptDisabled is true.
 // If Stdin or Stdout are nil, the default values will be used.
 func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []string, Stdin io.ReadCloser, Stdout io.WriteCloser) (map[string]string, error) {
+	return runPrompts(config, varsToSkip, nil, Stdin, Stdout)
+}
+
+func runPrompts(draftConfig *config.DraftConfig, varsToSkip []string, knownInputs map[string]string, Stdin io.ReadCloser, Stdout io.WriteCloser) (map[string]string, error) {
 	skipMap := make(map[string]interface{})
 	for _, v := range varsToSkip {
 		skipMap[v] = interface{}(nil)
 	}
 
 	inputs := make(map[string]string)
+	maps.Copy(inputs, knownInputs)
 
-	for _, customPrompt := range config.Variables {
+	for _, customPrompt := range draftConfig.Variables {
 		promptVariableName := customPrompt.Name
 		if _, ok := skipMap[promptVariableName]; ok {
 			log.Debugf("Skipping prompt for %s", promptVariableName)
@@ -37,7 +53,10 @@ func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []st
 		}
 		if customPrompt.IsPromptDisabled {
 			log.Debugf("Skipping prompt for %s as it has IsPromptDisabled=true", promptVariableName)
-			noPromptDefaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, inputs)
+			noPromptDefaultValue, err := GetVariableDefaultValue(promptVariableName, draftConfig.VariableDefaults, inputs)
+			if err != nil {
+				return nil, err
+			}
 			if noPromptDefaultValue == "" {
 				return nil, fmt.Errorf("IsPromptDisabled is true for %s but no default value was found", promptVariableName)
 			}
@@ -54,7 +73,10 @@ func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []st
 			}
 			inputs[promptVariableName] = input
 		} else {
-			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, inputs)
+			defaultValue, err := GetVariableDefaultValue(promptVariableName, draftConfig.VariableDefaults, inputs)
+			if err != nil {
+				return nil, err
+			}
 
 			stringInput, err := RunDefaultableStringPrompt(customPrompt, defaultValue, nil, Stdin, Stdout)
 			if err != nil {
@@ -65,29 +87,20 @@ func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []st
 	}
 
 	// Substitute the default value for variables where the user didn't enter anything
-	for _, variableDefault := range config.VariableDefaults {
-		if inputs[variableDefault.Name] == "" {
-			inputs[variableDefault.Name] = variableDefault.Value
-		}
-	}
-
-	return inputs, nil
+	return config.ResolveVariableDefaults(draftConfig.VariableDefaults, inputs)
 }
 
-// GetVariableDefaultValue returns the default value for a variable, if one is set in variableDefaults from a ReferenceVar or literal VariableDefault.Value in that order.
-func GetVariableDefaultValue(variableName string, variableDefaults []config.BuilderVarDefault, inputs map[string]string) string {
-	defaultValue := ""
-	for _, variableDefault := range variableDefaults {
-		if variableDefault.Name == variableName {
-			defaultValue = variableDefault.Value
-			log.Debugf("setting default value for %s to %s from variable default rule", variableName, defaultValue)
-			if variableDefault.ReferenceVar != "" && inputs[variableDefault.ReferenceVar] != "" {
-				defaultValue = inputs[variableDefault.ReferenceVar]
-				log.Debugf("setting default value for %s to %s from referenceVar %s", variableName, defaultValue, variableDefault.ReferenceVar)
-			}
-		}
+// GetVariableDefaultValue returns the default value for a variable, if one is set in variableDefaults from a ReferenceVar or VariableDefault.Value in that order.
+// Expressions in the value such as {{APPNAME}}-svc or {{lower APPNAME}} are evaluated, using the defaults of the variables they use when those aren't in inputs yet.
+func GetVariableDefaultValue(variableName string, variableDefaults []config.BuilderVarDefault, inputs map[string]string) (string, error) {
+	defaultValue, err := config.ResolveVariableDefault(variableName, variableDefaults, inputs)
+	if err != nil {
+		return "", err
+	}
+	if defaultValue != "" {
+		log.Debugf("setting default value for %s to %s from variable default rule", variableName, defaultValue)
 	}
-	return defaultValue
+	return defaultValue, nil
 }
 
 func RunBoolPrompt(customPrompt config.BuilderVar, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
@@ -167,3 +180,51 @@ func GetInputFromPrompt(desiredInput string) string {
 
 	return input
 }
+
+// SelectKustomizeOverlay returns the kustomize overlay in dest to use. The given overlay is returned if set, the only
+// overlay if there is one, and the user is prompted to pick one if there are several. An empty string is returned
+// if dest has no overlays.
+func SelectKustomizeOverlay(dest, overlay string) (string, error) {
+	overlays, err := filematches.FindKustomizeOverlays(dest)
+	if err != nil {
+		return "", err
+	}
+	return selectOption("kustomize overlay", overlays, overlay)
+}
+
+// SelectHelmChart returns the path, relative to dest, of the helm chart in dest to use. The given chart is returned
+// if set, the only chart if there is one, and the user is prompted to pick one if there are several. An empty
+// string is returned if dest has no charts.
+func SelectHelmChart(dest, chart string) (string, error) {
+	charts, err := filematches.FindHelmCharts(dest)
+	if err != nil {
+		return "", err
+	}
+	if chart != "" {
+		chart = path.Clean(chart)
+	}
+	return selectOption("helm chart", charts, chart)
+}
+
+func selectOption(name string, options []string, selected string) (string, error) {
+	if selected != "" {
+		if len(options) > 0 && !slices.Contains(options, selected) {
+			return "", fmt.Errorf("%s %s not found, available: %v", name, selected, options)
+		}
+		return selected, nil
+	}
+
+	switch len(options) {
+	case 0:
+		return "", nil
+	case 1:
+		return options[0], nil
+	}
+
+	selection := &promptui.Select{
+		Label: "Select " + name,
+		Items: options,
+	}
+	_, selected, err := selection.Run()
+	return selected, err
+}
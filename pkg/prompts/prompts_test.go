@@ -2,6 +2,7 @@ package prompts
 
 import (
 	"io"
+	"reflect"
 	"testing"
 
 	"github.com/Azure/draft/pkg/config"
@@ -67,17 +68,75 @@ func TestGetVariableDefaultValue(t *testing.T) {
 			},
 			inputs: map[string]string{},
 			want:   "before-default-value",
+		}, {
+			testName:     "expressionUsesInputs",
+			variableName: "SERVICENAME",
+			variableDefaults: []config.BuilderVarDefault{
+				{
+					Name:  "SERVICENAME",
+					Value: "{{lower APPNAME}}-svc",
+				},
+			},
+			inputs: map[string]string{
+				"APPNAME": "MyApp",
+			},
+			want: "myapp-svc",
+		}, {
+			testName:     "expressionUsesDefaultsInDependencyOrder",
+			variableName: "IMAGE",
+			variableDefaults: []config.BuilderVarDefault{
+				{
+					Name:  "IMAGE",
+					Value: "{{AZURECONTAINERREGISTRY}}.azurecr.io/{{CONTAINERNAME}}",
+				}, {
+					Name:  "AZURECONTAINERREGISTRY",
+					Value: "myregistry",
+				}, {
+					Name:  "CONTAINERNAME",
+					Value: "{{APPNAME}}",
+				},
+			},
+			inputs: map[string]string{
+				"APPNAME": "myapp",
+			},
+			want: "myregistry.azurecr.io/myapp",
+		}, {
+			testName:     "expressionWithMissingVariableIsEmptyString",
+			variableName: "SERVICENAME",
+			variableDefaults: []config.BuilderVarDefault{
+				{
+					Name:  "SERVICENAME",
+					Value: "{{APPNAME}}-svc",
+				},
+			},
+			inputs: map[string]string{},
+			want:   "",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
-			if got := GetVariableDefaultValue(tt.variableName, tt.variableDefaults, tt.inputs); got != tt.want {
+			got, err := GetVariableDefaultValue(tt.variableName, tt.variableDefaults, tt.inputs)
+			if err != nil {
+				t.Errorf("GetVariableDefaultValue() error = %v", err)
+			}
+			if got != tt.want {
 				t.Errorf("GetVariableDefaultValue() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestGetVariableDefaultValueCycle(t *testing.T) {
+	variableDefaults := []config.BuilderVarDefault{
+		{Name: "A", Value: "{{B}}-a"},
+		{Name: "B", Value: "{{C}}-b"},
+		{Name: "C", Value: "{{A}}-c"},
+	}
+	if _, err := GetVariableDefaultValue("A", variableDefaults, map[string]string{}); err == nil {
+		t.Errorf("GetVariableDefaultValue() expected a cycle error")
+	}
+}
+
 func TestRunStringPrompt(t *testing.T) {
 	tests := []struct {
 		testName     string
@@ -246,3 +305,25 @@ func TestRunPromptsFromConfigWithSkipsIO(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPromptsFromConfigWithInputs(t *testing.T) {
+	draftConfig := config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "PORT", Description: "the port, known from an earlier phase so it isn't prompted"},
+			{Name: "SERVICEPORT", Description: "the service port, defaulting to the known port", IsPromptDisabled: true},
+		},
+		VariableDefaults: []config.BuilderVarDefault{
+			{Name: "PORT", Value: "80"},
+			{Name: "SERVICEPORT", ReferenceVar: "PORT"},
+		},
+	}
+
+	got, err := RunPromptsFromConfigWithInputs(&draftConfig, map[string]string{"PORT": "8080"})
+	if err != nil {
+		t.Fatalf("TestRunPromptsFromConfigWithInputs() error = %v", err)
+	}
+	want := map[string]string{"PORT": "8080", "SERVICEPORT": "8080"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestRunPromptsFromConfigWithInputs() inputs = %v, want %v", got, want)
+	}
+}
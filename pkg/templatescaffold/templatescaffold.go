@@ -0,0 +1,117 @@
+package templatescaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/templatetest"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+//go:embed all:skeletons
+var skeletons embed.FS
+
+const skeletonsDir = "skeletons"
+
+// DefaultProvider is the provider addons are created for when none is given
+const DefaultProvider = "azure"
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// Pack is a template pack to create
+type Pack struct {
+	Kind string
+	Name string
+	// Provider is the directory under addons an addon is created in
+	Provider string
+}
+
+// Dir returns the directory of the pack under root, such as root/deployments/name or root/addons/azure/name
+func (p Pack) Dir(root string) string {
+	if p.Kind == templatetest.KindAddon {
+		return filepath.Join(root, templatetest.KindDirs[templatetest.KindAddon], p.Provider, p.Name)
+	}
+	return filepath.Join(root, templatetest.KindDirs[p.Kind], p.Name)
+}
+
+// Validate checks the pack's kind, and that its name and provider can be used as directory names and in variables
+func (p Pack) Validate() error {
+	if _, ok := templatetest.KindDirs[p.Kind]; !ok {
+		return fmt.Errorf("unknown template kind %q, must be one of dockerfile, deployment, workflow or addon", p.Kind)
+	}
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid template name %q, must be lowercase letters and digits separated by - or _", p.Name)
+	}
+	if p.Kind == templatetest.KindAddon && !namePattern.MatchString(p.Provider) {
+		return fmt.Errorf("invalid provider %q, must be lowercase letters and digits separated by - or _", p.Provider)
+	}
+	return nil
+}
+
+// Create writes the skeleton of the pack to its directory under root and returns the directory. The skeleton has a
+// documented draft.yaml, sample template files and the example values of draft template test in testdata.
+func Create(root string, p Pack, templateWriter templatewriter.TemplateWriter) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	dir := p.Dir(root)
+	exists, err := osutil.Exists(dir)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("%s already exists", dir)
+	}
+
+	srcDir := path.Join(skeletonsDir, p.Kind)
+	err = fs.WalkDir(skeletons, srcDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		relPath, err := render(strings.TrimPrefix(filePath, srcDir+"/"), p)
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(skeletons, filePath)
+		if err != nil {
+			return err
+		}
+		rendered, err := render(string(content), p)
+		if err != nil {
+			return fmt.Errorf("rendering %s: %w", relPath, err)
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(relPath))
+		if err = templateWriter.EnsureDirectory(filepath.Dir(dest)); err != nil {
+			return err
+		}
+		return templateWriter.WriteFile(dest, []byte(rendered))
+	})
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// render fills in the pack's name and provider. Skeletons use [[ ]] as delimiters so the {{VARIABLE}} placeholders
+// of the template files, and the ${{ }} expressions of workflows, are left as they are.
+func render(text string, p Pack) (string, error) {
+	tmpl, err := template.New("").Delims("[[", "]]").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, p); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
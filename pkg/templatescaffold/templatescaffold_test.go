@@ -0,0 +1,74 @@
+package templatescaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatetest"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		pack     Pack
+		dir      string
+		expected []string
+	}{
+		{Pack{Kind: templatetest.KindDockerfile, Name: "newlang"}, "dockerfiles/newlang", []string{"Dockerfile", "dockerignore"}},
+		{Pack{Kind: templatetest.KindDeployment, Name: "newdeploy"}, "deployments/newdeploy", []string{"manifests/deployment.yaml", "manifests/service.yaml"}},
+		{Pack{Kind: templatetest.KindWorkflow, Name: "newdeploy"}, "workflows/newdeploy", []string{".github/workflows/newdeploy.yml"}},
+		{Pack{Kind: templatetest.KindAddon, Name: "new_addon", Provider: DefaultProvider}, "addons/azure/new_addon", []string{"ingress.yaml"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pack.Kind, func(t *testing.T) {
+			root := t.TempDir()
+			dir, err := Create(root, test.pack, &writers.LocalFSWriter{})
+			assert.Nil(t, err)
+			assert.Equal(t, filepath.Join(root, test.dir), dir)
+			for _, file := range append(test.expected, "draft.yaml", "testdata/variables.yaml") {
+				assert.FileExists(t, filepath.Join(dir, filepath.FromSlash(file)))
+			}
+
+			draftConfig, err := os.ReadFile(filepath.Join(dir, "draft.yaml"))
+			assert.Nil(t, err)
+			assert.Contains(t, string(draftConfig), test.pack.Name)
+
+			violations, err := templatetest.Test(dir, templatetest.Options{UpdateGolden: true})
+			assert.Nil(t, err)
+			assert.Empty(t, violations)
+			violations, err = templatetest.Test(dir, templatetest.Options{})
+			assert.Nil(t, err)
+			assert.Empty(t, violations)
+
+			_, err = Create(root, test.pack, &writers.LocalFSWriter{})
+			assert.ErrorContains(t, err, "already exists")
+		})
+	}
+}
+
+func TestCreateInvalid(t *testing.T) {
+	tests := []struct {
+		pack     Pack
+		expected string
+	}{
+		{Pack{Kind: "chart", Name: "test"}, `unknown template kind "chart"`},
+		{Pack{Kind: templatetest.KindDockerfile, Name: "Test"}, `invalid template name "Test"`},
+		{Pack{Kind: templatetest.KindDockerfile, Name: "../test"}, `invalid template name "../test"`},
+		{Pack{Kind: templatetest.KindAddon, Name: "test"}, `invalid provider ""`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			root := t.TempDir()
+			_, err := Create(root, test.pack, &writers.LocalFSWriter{})
+			assert.ErrorContains(t, err, test.expected)
+			entries, err := os.ReadDir(root)
+			assert.Nil(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
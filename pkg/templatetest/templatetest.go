@@ -0,0 +1,524 @@
+// Package templatetest checks template packs, such as the ones under template/: that the variables their files use
+// are declared in draft.yaml and the declared ones are used, that they render with example values into valid yaml
+// and Dockerfiles, and that the rendered files match the golden files stored with the pack.
+package templatetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/addons"
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/dockerfile"
+	"github.com/Azure/draft/pkg/k8svalidation"
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/variables"
+)
+
+const (
+	KindDockerfile = "dockerfile"
+	KindDeployment = "deployment"
+	KindWorkflow   = "workflow"
+	KindAddon      = "addon"
+)
+
+// KindDirs are the directories the packs of each kind are kept in, addons being one level deeper under their provider
+var KindDirs = map[string]string{
+	KindDockerfile: "dockerfiles",
+	KindDeployment: "deployments",
+	KindWorkflow:   "workflows",
+	KindAddon:      "addons",
+}
+
+const (
+	// CheckUndeclaredVariable reports variables used by the files of a pack that draft.yaml doesn't declare
+	CheckUndeclaredVariable = "template-undeclared-variable"
+	// CheckUnusedVariable reports variables declared in draft.yaml that nothing uses
+	CheckUnusedVariable = "template-unused-variable"
+	// CheckMissingExample reports variables without an example value, default or test value to render with
+	CheckMissingExample = "template-missing-example"
+	// CheckRender reports packs that fail to render
+	CheckRender = "template-render"
+	// CheckInvalidYAML reports rendered yaml files that don't parse
+	CheckInvalidYAML = "template-invalid-yaml"
+	// CheckInvalidDockerfile reports rendered Dockerfiles without a FROM or with unknown instructions
+	CheckInvalidDockerfile = "template-invalid-dockerfile"
+	// CheckGolden reports rendered files that differ from the golden files
+	CheckGolden = "template-golden"
+
+	configFileName = "draft.yaml"
+	// VariablesFile in the pack's testdata directory sets the values the pack is rendered with, overriding the
+	// example values and defaults from draft.yaml
+	VariablesFile = "variables.yaml"
+	// GoldenDir in the pack's testdata directory holds the expected rendered files
+	GoldenDir = "golden"
+)
+
+var dockerfileInstructions = map[string]bool{
+	"ADD": true, "ARG": true, "CMD": true, "COPY": true, "ENTRYPOINT": true, "ENV": true, "EXPOSE": true,
+	"FROM": true, "HEALTHCHECK": true, "LABEL": true, "MAINTAINER": true, "ONBUILD": true, "RUN": true,
+	"SHELL": true, "STOPSIGNAL": true, "USER": true, "VOLUME": true, "WORKDIR": true,
+}
+
+// Options changes how packs are tested
+type Options struct {
+	// UpdateGolden replaces the golden files of the pack with the rendered files instead of comparing them
+	UpdateGolden bool
+}
+
+// Pack is a template pack on disk
+type Pack struct {
+	Kind   string
+	Dir    string
+	Config *config.DraftConfig
+
+	// templates is the directory of the kind's directory, and srcDir the pack's path in it, such as deployments/helm
+	templates fs.FS
+	srcDir    string
+	// references are the variables of an addon read from the project's resources
+	references []string
+}
+
+// Load reads the pack in dir, whose kind comes from the directory it is in
+func Load(dir string) (*Pack, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := filepath.Dir(absDir)
+	root, srcDir, kind := filepath.Dir(parent), path.Join(filepath.Base(parent), filepath.Base(absDir)), ""
+	for k, kindDir := range KindDirs {
+		if k != KindAddon && filepath.Base(parent) == kindDir {
+			kind = k
+		}
+	}
+	if filepath.Base(filepath.Dir(parent)) == KindDirs[KindAddon] {
+		kind = KindAddon
+		root = filepath.Dir(filepath.Dir(parent))
+		srcDir = path.Join(KindDirs[KindAddon], filepath.Base(parent), filepath.Base(absDir))
+	}
+	if kind == "" {
+		return nil, fmt.Errorf("%s must be in a dockerfiles, deployments, workflows or addons/<provider> directory", dir)
+	}
+
+	content, err := os.ReadFile(filepath.Join(absDir, configFileName))
+	if err != nil {
+		return nil, err
+	}
+	pack := &Pack{Kind: kind, Dir: dir, templates: os.DirFS(root), srcDir: srcDir}
+	if kind == KindAddon {
+		var addonConfig addons.AddonConfig
+		if err = config.DecodeStrict(content, &addonConfig); err != nil {
+			return nil, fmt.Errorf("%s: %w", configFileName, err)
+		}
+		if err = addonConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", configFileName, err)
+		}
+		pack.Config = &addonConfig.DraftConfig
+		for _, resources := range addonConfig.ReferenceComponents {
+			for _, resource := range resources {
+				pack.references = append(pack.references, resource.Name)
+			}
+		}
+	} else {
+		pack.Config = &config.DraftConfig{}
+		if err = config.DecodeStrict(content, pack.Config); err != nil {
+			return nil, fmt.Errorf("%s: %w", configFileName, err)
+		}
+		if err = pack.Config.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", configFileName, err)
+		}
+	}
+	return pack, nil
+}
+
+// Test checks the pack in dir
+func Test(dir string, opts Options) ([]policy.Violation, error) {
+	pack, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pack.Test(opts)
+}
+
+// Test checks the pack's variables, renders it with example values, validates the rendered files and compares
+// them with the golden files
+func (p *Pack) Test(opts Options) ([]policy.Violation, error) {
+	templateFiles, err := p.templateFiles()
+	if err != nil {
+		return nil, err
+	}
+	violations := p.checkVariables(templateFiles)
+
+	inputs, missing, err := p.exampleInputs()
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, missing...)
+
+	rendered, err := p.Render(inputs)
+	if err != nil {
+		return append(violations, policy.Violation{
+			Rule:     CheckRender,
+			Severity: policy.SeverityError,
+			Message:  err.Error(),
+			Source:   configFileName,
+		}), nil
+	}
+	violations = append(violations, p.checkRendered(rendered)...)
+
+	goldenViolations, err := p.checkGolden(rendered, opts.UpdateGolden)
+	if err != nil {
+		return nil, err
+	}
+	return append(violations, goldenViolations...), nil
+}
+
+// Render renders the pack with inputs, returning the rendered files keyed by their path relative to the destination
+func (p *Pack) Render(inputs map[string]string) (map[string][]byte, error) {
+	w := &writers.FileMapWriter{FileMap: map[string][]byte{}}
+	var err error
+	if p.Kind == KindDeployment {
+		err = deployments.RenderTemplates(p.templates, p.srcDir, "", p.Config, nil, inputs, w)
+	} else {
+		err = osutil.CopyDir(p.templates, p.srcDir, "", p.Config, inputs, w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w.FileMap, nil
+}
+
+// templateFiles returns the files of the pack other than draft.yaml and its test data, keyed by their path in the pack
+func (p *Pack) templateFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := fs.WalkDir(p.templates, p.srcDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath := strings.TrimPrefix(strings.TrimPrefix(filePath, p.srcDir), "/")
+		if d.IsDir() {
+			if relPath == osutil.TemplateTestDataDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if relPath == configFileName {
+			return nil
+		}
+		content, err := fs.ReadFile(p.templates, filePath)
+		if err != nil {
+			return err
+		}
+		files[relPath] = content
+		return nil
+	})
+	return files, err
+}
+
+// declaredVariables returns the variables the pack's files may use: the ones declared in draft.yaml, addon
+// references and the variables draft sets itself while rendering
+func (p *Pack) declaredVariables() map[string]bool {
+	declared := make(map[string]bool)
+	for _, variable := range p.Config.Variables {
+		declared[variable.Name] = true
+	}
+	for _, variableDefault := range p.Config.VariableDefaults {
+		declared[variableDefault.Name] = true
+	}
+	for _, reference := range p.references {
+		declared[reference] = true
+	}
+	if p.Kind == KindDeployment {
+		for _, name := range deployments.GeneratedVariables {
+			declared[name] = true
+		}
+	}
+	return declared
+}
+
+// checkVariables reports the variables used by the pack that aren't declared, and the declared ones that aren't used
+// by its files, name overrides, file conditions or the defaults of other variables
+func (p *Pack) checkVariables(templateFiles map[string][]byte) []policy.Violation {
+	var violations []policy.Violation
+	declared := p.declaredVariables()
+	used := make(map[string]bool)
+
+	useAll := func(source string, names []string) {
+		for _, name := range names {
+			used[name] = true
+			if !declared[name] {
+				violations = append(violations, policy.Violation{
+					Rule:     CheckUndeclaredVariable,
+					Severity: policy.SeverityError,
+					Message:  fmt.Sprintf("{{%s}} is used but not declared in %s", name, configFileName),
+					Source:   source,
+				})
+			}
+		}
+	}
+
+	for _, filePath := range sortedKeys(templateFiles) {
+		useAll(filePath, osutil.TemplateVariables(string(templateFiles[filePath])))
+	}
+	for _, override := range p.Config.NameOverrides {
+		useAll(configFileName, osutil.TemplateVariables(override.Prefix+override.Destination))
+	}
+	for _, condition := range p.Config.FileConditions {
+		useAll(configFileName, []string{condition.Variable})
+	}
+	for _, variableDefault := range p.Config.VariableDefaults {
+		useAll(configFileName, variableDefault.References())
+	}
+
+	for _, variable := range p.Config.Variables {
+		if !used[variable.Name] {
+			violations = append(violations, policy.Violation{
+				Rule:     CheckUnusedVariable,
+				Severity: policy.SeverityError,
+				Message:  fmt.Sprintf("variable %s is declared but not used", variable.Name),
+				Source:   configFileName,
+			})
+		}
+	}
+	return violations
+}
+
+// exampleInputs returns the values the pack is rendered with: the values in testdata/variables.yaml, else the first
+// example value of each variable, else its default. Bool variables with none of them are false, and other variables
+// with none of them are reported and set to a placeholder.
+func (p *Pack) exampleInputs() (map[string]string, []policy.Violation, error) {
+	inputs := make(map[string]string)
+	for _, variable := range p.Config.Variables {
+		if len(variable.ExampleValues) > 0 {
+			inputs[variable.Name] = variable.ExampleValues[0]
+		}
+	}
+	for _, reference := range p.references {
+		inputs[reference] = "example-" + reference
+	}
+
+	testValuesPath := path.Join(p.srcDir, osutil.TemplateTestDataDir, VariablesFile)
+	if content, err := fs.ReadFile(p.templates, testValuesPath); err == nil {
+		testValues, err := variables.ParseYAML(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", testValuesPath, err)
+		}
+		maps.Copy(inputs, testValues)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	inputs, err := config.ResolveVariableDefaults(p.Config.VariableDefaults, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var violations []policy.Violation
+	for _, variable := range p.Config.Variables {
+		if _, ok := inputs[variable.Name]; ok {
+			continue
+		}
+		if variable.VarType == "bool" {
+			inputs[variable.Name] = "false"
+			continue
+		}
+		placeholder := strings.ToLower(variable.Name)
+		inputs[variable.Name] = placeholder
+		violations = append(violations, policy.Violation{
+			Rule:     CheckMissingExample,
+			Severity: policy.SeverityWarning,
+			Message:  fmt.Sprintf("variable %s has no example value, default or value in %s/%s, rendering it as %q", variable.Name, osutil.TemplateTestDataDir, VariablesFile, placeholder),
+			Source:   configFileName,
+		})
+	}
+	return inputs, violations, nil
+}
+
+// checkRendered validates the rendered yaml files and Dockerfiles, and that the rendered deployments build
+func (p *Pack) checkRendered(rendered map[string][]byte) []policy.Violation {
+	var violations []policy.Violation
+	for _, filePath := range sortedKeys(rendered) {
+		content := rendered[filePath]
+		switch {
+		case dockerfile.IsDockerfile(path.Base(filePath)):
+			if message := checkDockerfile(content); message != "" {
+				violations = append(violations, policy.Violation{
+					Rule:     CheckInvalidDockerfile,
+					Severity: policy.SeverityError,
+					Message:  "rendered Dockerfile is invalid: " + message,
+					Source:   filePath,
+				})
+			}
+		case isYAMLFile(filePath) && !isHelmTemplate(filePath, rendered):
+			if err := checkYAML(content); err != nil {
+				violations = append(violations, policy.Violation{
+					Rule:     CheckInvalidYAML,
+					Severity: policy.SeverityError,
+					Message:  "rendered file isn't valid yaml: " + err.Error(),
+					Source:   filePath,
+				})
+			}
+		}
+	}
+
+	if p.Kind == KindDeployment {
+		if _, err := k8svalidation.RenderManifests(rendered); err != nil {
+			violations = append(violations, policy.Violation{
+				Rule:     CheckRender,
+				Severity: policy.SeverityError,
+				Message:  fmt.Sprintf("rendering the helm charts and kustomizations: %s", err),
+				Source:   configFileName,
+			})
+		}
+	}
+	return violations
+}
+
+// checkGolden compares the rendered files with the golden files, when the pack has any, or replaces the golden files
+// with the rendered files when update is set
+func (p *Pack) checkGolden(rendered map[string][]byte, update bool) ([]policy.Violation, error) {
+	goldenPath := filepath.Join(p.Dir, osutil.TemplateTestDataDir, GoldenDir)
+	if update {
+		if err := os.RemoveAll(goldenPath); err != nil {
+			return nil, err
+		}
+		for filePath, content := range rendered {
+			dest := filepath.Join(goldenPath, filepath.FromSlash(filePath))
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(dest, content, 0644); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	golden := make(map[string][]byte)
+	err := filepath.WalkDir(goldenPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(goldenPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		golden[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []policy.Violation
+	mismatch := func(filePath, message string) {
+		violations = append(violations, policy.Violation{
+			Rule:     CheckGolden,
+			Severity: policy.SeverityError,
+			Message:  message,
+			Source:   filePath,
+		})
+	}
+	for _, filePath := range sortedKeys(rendered) {
+		expected, ok := golden[filePath]
+		switch {
+		case !ok:
+			mismatch(filePath, "rendered file has no golden file")
+		case !bytes.Equal(expected, rendered[filePath]):
+			mismatch(filePath, fmt.Sprintf("rendered file differs from the golden file at line %d", firstDifferentLine(expected, rendered[filePath])))
+		}
+	}
+	for _, filePath := range sortedKeys(golden) {
+		if _, ok := rendered[filePath]; !ok {
+			mismatch(filePath, "golden file isn't rendered")
+		}
+	}
+	return violations, nil
+}
+
+func checkDockerfile(content []byte) string {
+	parsed := dockerfile.Parse(content)
+	if parsed.Stages == 0 {
+		return "no FROM instruction"
+	}
+	for _, instruction := range parsed.Instructions {
+		if !dockerfileInstructions[instruction.Command] {
+			return fmt.Sprintf("line %d: unknown instruction %s", instruction.Line, instruction.Command)
+		}
+	}
+	return ""
+}
+
+func checkYAML(content []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isYAMLFile(filePath string) bool {
+	ext := path.Ext(filePath)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// isHelmTemplate returns whether the file is in the templates directory of a helm chart, which isn't plain yaml
+func isHelmTemplate(filePath string, files map[string][]byte) bool {
+	for dir := path.Dir(filePath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if path.Base(dir) != "templates" {
+			continue
+		}
+		if _, ok := files[path.Join(path.Dir(dir), "Chart.yaml")]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func firstDifferentLine(a, b []byte) int {
+	aLines, bLines := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := 0; i < len(aLines) && i < len(bLines); i++ {
+		if aLines[i] != bLines[i] {
+			return i + 1
+		}
+	}
+	if len(aLines) < len(bLines) {
+		return len(aLines) + 1
+	}
+	return len(bLines) + 1
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package templatetest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/policy"
+)
+
+const testDraftConfig = `language: test
+variables:
+  - name: "PORT"
+    exampleValues: ["8080"]
+  - name: "VERSION"
+variableDefaults:
+  - name: "VERSION"
+    value: "1.0"
+`
+
+const testDockerfile = "FROM test:{{VERSION}}\nENV PORT={{PORT}}\nEXPOSE {{PORT}}\n"
+
+func TestTemplatePacks(t *testing.T) {
+	var dirs []string
+	for _, pattern := range []string{"../../template/dockerfiles/*", "../../template/deployments/*", "../../template/workflows/*", "../../template/addons/*/*"} {
+		matches, err := filepath.Glob(pattern)
+		assert.Nil(t, err)
+		dirs = append(dirs, matches...)
+	}
+	assert.NotEmpty(t, dirs)
+
+	for _, dir := range dirs {
+		t.Run(dir, func(t *testing.T) {
+			violations, err := Test(dir, Options{})
+			assert.Nil(t, err)
+			assert.Empty(t, violations)
+		})
+	}
+}
+
+func TestTestVariables(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"draft.yaml": testDraftConfig,
+		"Dockerfile": testDockerfile + "CMD [\"{{COMMAND}}\"]\n",
+	})
+
+	violations, err := Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{CheckUndeclaredVariable, CheckRender}, rules(violations))
+	assert.Equal(t, "Dockerfile", violations[0].Source)
+	assert.Contains(t, violations[0].Message, "{{COMMAND}}")
+
+	dir = writePack(t, map[string]string{
+		"draft.yaml": "variables:\n  - name: \"PORT\"\n    exampleValues: [\"8080\"]\n  - name: \"SPARE\"\n    exampleValues: [\"x\"]\n",
+		"Dockerfile": "FROM test\nEXPOSE {{PORT}}\n",
+	})
+	violations, err = Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{CheckUnusedVariable}, rules(violations))
+	assert.Contains(t, violations[0].Message, "SPARE")
+}
+
+func TestTestMissingExample(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"draft.yaml": "variables:\n  - name: \"IMAGE\"\n  - name: \"DEBUG\"\n    type: \"bool\"\n",
+		"Dockerfile": "FROM {{IMAGE}}\nENV DEBUG={{DEBUG}}\n",
+	})
+
+	violations, err := Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{CheckMissingExample}, rules(violations))
+	assert.Equal(t, policy.SeverityWarning, violations[0].Severity)
+	assert.Contains(t, violations[0].Message, "IMAGE")
+}
+
+func TestTestInvalidFiles(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"draft.yaml":        testDraftConfig,
+		"Dockerfile":        "ENV PORT={{PORT}}\nRUNN make\n",
+		"Dockerfile.slim":   "FROM test:{{VERSION}}\nRUNN make\n",
+		"config/app.yaml":   "port: {{PORT}}\n  bad: indent\n",
+		"config/other.yaml": "port: {{PORT}}\n",
+	})
+
+	violations, err := Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{CheckInvalidDockerfile, CheckInvalidDockerfile, CheckInvalidYAML}, rules(violations))
+	assert.Contains(t, violations[0].Message, "no FROM instruction")
+	assert.Contains(t, violations[1].Message, "unknown instruction RUNN")
+	assert.Equal(t, "config/app.yaml", violations[2].Source)
+}
+
+func TestTestGolden(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"draft.yaml":              testDraftConfig,
+		"Dockerfile":              testDockerfile,
+		"testdata/variables.yaml": "PORT: 3000\n",
+	})
+	goldenDockerfile := filepath.Join(dir, "testdata", "golden", "Dockerfile")
+
+	violations, err := Test(dir, Options{UpdateGolden: true})
+	assert.Nil(t, err)
+	assert.Empty(t, violations)
+	golden, err := os.ReadFile(goldenDockerfile)
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM test:1.0\nENV PORT=3000\nEXPOSE 3000\n", string(golden))
+	_, err = os.Stat(filepath.Join(dir, "testdata", "golden", "testdata"))
+	assert.True(t, os.IsNotExist(err), "testdata shouldn't be rendered")
+
+	violations, err = Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Empty(t, violations)
+
+	appendFile(t, filepath.Join(dir, "Dockerfile"), "USER 1000\n")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "testdata", "golden", "Dockerfile.old"), []byte("FROM test\n"), 0644))
+	violations, err = Test(dir, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{CheckGolden, CheckGolden}, rules(violations))
+	assert.Contains(t, violations[0].Message, "differs from the golden file at line 4")
+	assert.Equal(t, "Dockerfile.old", violations[1].Source)
+}
+
+func TestLoad(t *testing.T) {
+	dir := writePack(t, map[string]string{"draft.yaml": testDraftConfig, "Dockerfile": testDockerfile})
+	pack, err := Load(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, KindDockerfile, pack.Kind)
+
+	_, err = Load(t.TempDir())
+	assert.ErrorContains(t, err, "must be in a dockerfiles, deployments, workflows or addons/<provider> directory")
+
+	dir = writePack(t, map[string]string{"draft.yaml": testDraftConfig + "variableDefault:\n  - name: PORT\n"})
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "field variableDefault not found")
+}
+
+// writePack writes the files of a dockerfile pack into a dockerfiles directory and returns the pack's directory
+func writePack(t *testing.T, files map[string]string) string {
+	dir := filepath.Join(t.TempDir(), "dockerfiles", "test")
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+	return dir
+}
+
+func appendFile(t *testing.T, filePath, content string) {
+	existing, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filePath, append(existing, content...), 0644))
+}
+
+func rules(violations []policy.Violation) []string {
+	var ids []string
+	for _, violation := range violations {
+		ids = append(ids, violation.Rule)
+	}
+	return ids
+}
@@ -1,5 +1,12 @@
 package writers
 
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
 type FileMapWriter struct {
 	FileMap map[string][]byte
 }
@@ -16,3 +23,22 @@ func (w *FileMapWriter) WriteFile(path string, data []byte) error {
 func (w *FileMapWriter) EnsureDirectory(path string) error {
 	return nil
 }
+
+// WriteFileMap writes the files of a file map, such as one rendered by a FileMapWriter, with the template writer
+func WriteFileMap(fileMap map[string][]byte, templateWriter templatewriter.TemplateWriter) error {
+	paths := make([]string, 0, len(fileMap))
+	for path := range fileMap {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := templateWriter.EnsureDirectory(filepath.Dir(path)); err != nil {
+			return err
+		}
+		if err := templateWriter.WriteFile(path, fileMap[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package writers
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/osutil"
 	"github.com/Azure/draft/template"
 )
@@ -13,10 +15,86 @@ func TestCopyDirToFileMap(t *testing.T) {
 
 	templatewriter := &FileMapWriter{}
 	err := osutil.CopyDir(template.Dockerfiles, "dockerfiles/javascript", "/test/dir", nil, map[string]string{
-		"PORT":    "8080",
-		"VERSION": "14",
+		"PORT":         "8080",
+		"VERSION":      "14",
+		"BUILDERIMAGE": "node:20-bookworm-slim",
+		"RUNTIMEIMAGE": "node:20-bookworm-slim",
 	}, templatewriter)
 	assert.Nil(t, err)
 	assert.NotNil(t, templatewriter.FileMap)
 	assert.NotNil(t, templatewriter.FileMap["/test/dir/Dockerfile"])
 }
+
+func TestCopyDirNameOverrides(t *testing.T) {
+	templateFS := fstest.MapFS{
+		"src/dockerignore":           {Data: []byte("Dockerfile")},
+		"src/charts/Chart.yaml":      {Data: []byte("name: {{APPNAME}}")},
+		"src/charts/values.yaml":     {Data: []byte("port: {{PORT}}")},
+		"src/{{APPNAME}}-config.txt": {Data: []byte("{{APPNAME}}")},
+		"src/production.yaml":        {Data: []byte("production")},
+	}
+	draftConfig := &config.DraftConfig{
+		NameOverrides: []config.FileNameOverride{
+			{Path: "dockerignore", Prefix: "."},
+			{Path: "charts", Destination: "charts/{{APPNAME}}"},
+			{Path: "production.yaml", Destination: "charts/{{APPNAME}}/production.yaml"},
+		},
+	}
+
+	templatewriter := &FileMapWriter{}
+	err := osutil.CopyDir(templateFS, "src", "/test/dir", draftConfig, map[string]string{
+		"APPNAME": "myapp",
+		"PORT":    "8080",
+	}, templatewriter)
+	assert.Nil(t, err)
+	assert.Equal(t, "Dockerfile", string(templatewriter.FileMap["/test/dir/.dockerignore"]))
+	assert.Equal(t, "name: myapp", string(templatewriter.FileMap["/test/dir/charts/myapp/Chart.yaml"]))
+	assert.Equal(t, "port: 8080", string(templatewriter.FileMap["/test/dir/charts/myapp/values.yaml"]))
+	assert.Equal(t, "production", string(templatewriter.FileMap["/test/dir/charts/myapp/production.yaml"]))
+	assert.Equal(t, "myapp", string(templatewriter.FileMap["/test/dir/myapp-config.txt"]))
+	assert.Len(t, templatewriter.FileMap, 5)
+
+	err = osutil.CopyDir(templateFS, "src", "/test/dir", draftConfig, map[string]string{"PORT": "8080"}, &FileMapWriter{})
+	assert.NotNil(t, err, "unsubstituted variables in destination paths should error")
+}
+
+func TestCopyDirFileConditions(t *testing.T) {
+	templateFS := fstest.MapFS{
+		"src/Dockerfile":          {Data: []byte("default")},
+		"src/Dockerfile.hardened": {Data: []byte("hardened")},
+		"src/extras/hpa.yaml":     {Data: []byte("hpa")},
+	}
+	draftConfig := &config.DraftConfig{
+		NameOverrides: []config.FileNameOverride{
+			{Path: "Dockerfile.hardened", Destination: "Dockerfile"},
+		},
+		FileConditions: []config.FileCondition{
+			{Path: "Dockerfile", Variable: "PROFILE", Values: []string{"hardened"}, Exclude: true},
+			{Path: "Dockerfile.hardened", Variable: "PROFILE", Values: []string{"hardened"}},
+			{Path: "extras", Variable: "ENABLE_EXTRAS", Values: []string{"true"}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		inputs        map[string]string
+		wantContent   string
+		wantExtraFile bool
+	}{
+		{"default profile", map[string]string{"PROFILE": "default", "ENABLE_EXTRAS": "false"}, "default", false},
+		{"hardened profile", map[string]string{"PROFILE": "hardened", "ENABLE_EXTRAS": "false"}, "hardened", false},
+		{"unset variables", map[string]string{}, "default", false},
+		{"extras enabled", map[string]string{"ENABLE_EXTRAS": "true"}, "default", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templatewriter := &FileMapWriter{}
+			err := osutil.CopyDir(templateFS, "src", "/test/dir", draftConfig, tt.inputs, templatewriter)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantContent, string(templatewriter.FileMap["/test/dir/Dockerfile"]))
+			_, hasExtraFile := templatewriter.FileMap["/test/dir/extras/hpa.yaml"]
+			assert.Equal(t, tt.wantExtraFile, hasExtraFile)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package variables
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/envfile"
+)
+
+// EnvPrefix is the prefix of the environment variables read as template variables, eg. DRAFT_VAR_APPNAME=myapp
+const EnvPrefix = "DRAFT_VAR_"
+
+// filePrefix marks a flag variable value to be read from a file, eg. --variable CERT=@cert.pem
+const filePrefix = "@"
+
+// Load returns the variables passed to a command outside of its prompts. Variables from the variable file are
+// overridden by DRAFT_VAR_ environment variables, which are overridden by --variable flags.
+func Load(flagVariables []string, variableFile string) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	if variableFile != "" {
+		fileVars, err := ReadFile(variableFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileVars {
+			vars[name] = value
+			log.Debugf("file variable %s=%s", name, value)
+		}
+	}
+
+	for name, value := range FromEnv(os.Environ()) {
+		vars[name] = value
+		log.Debugf("environment variable %s=%s", name, value)
+	}
+
+	flagVars, err := ParseFlags(flagVariables)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range flagVars {
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// ParseFlags parses NAME=VALUE flag variables. A value starting with @ is read from the file at the path that
+// follows it, and a value starting with @@ is kept as a literal value starting with @.
+func ParseFlags(flagVariables []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, flagVar := range flagVariables {
+		name, value, ok := strings.Cut(flagVar, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable format: %s", flagVar)
+		}
+
+		switch {
+		case strings.HasPrefix(value, filePrefix+filePrefix):
+			value = strings.TrimPrefix(value, filePrefix)
+			log.Debugf("flag variable %s=%s", name, value)
+		case strings.HasPrefix(value, filePrefix):
+			path := strings.TrimPrefix(value, filePrefix)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading value of variable %s: %w", name, err)
+			}
+			value = string(content)
+			log.Debugf("flag variable %s read from %s", name, path)
+		default:
+			log.Debugf("flag variable %s=%s", name, value)
+		}
+
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// FromEnv returns the variables set by DRAFT_VAR_ entries of an environment in the form of os.Environ
+func FromEnv(environ []string) map[string]string {
+	vars := make(map[string]string)
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, EnvPrefix) {
+			continue
+		}
+		if name := strings.TrimPrefix(key, EnvPrefix); name != "" {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// ReadFile reads a variable file. Files named .env or ending in .env are read as env files, anything else as a
+// yaml map of variable names to values.
+func ReadFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading variable file: %w", err)
+	}
+
+	if isEnvFile(path) {
+		envVars, err := envfile.Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing variable file %s: %w", path, err)
+		}
+		vars := make(map[string]string, len(envVars))
+		for _, envVar := range envVars {
+			vars[envVar.Key] = envVar.Value
+		}
+		return vars, nil
+	}
+
+	vars, err := ParseYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing variable file %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// ParseYAML parses a yaml map of variable names to single values
+func ParseYAML(content []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			vars[name] = ""
+		case map[string]any, []any:
+			return nil, fmt.Errorf("variable %s must be a single value", name)
+		default:
+			vars[name] = fmt.Sprint(v)
+		}
+	}
+	return vars, nil
+}
+
+func isEnvFile(path string) bool {
+	base := filepath.Base(path)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || filepath.Ext(base) == ".env"
+}
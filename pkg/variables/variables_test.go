@@ -0,0 +1,112 @@
+package variables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlags(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	cert := "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----\n"
+	assert.Nil(t, os.WriteFile(certPath, []byte(cert), 0644))
+
+	tests := []struct {
+		name          string
+		flagVariables []string
+		want          map[string]string
+		wantErr       bool
+	}{
+		{
+			name:          "plain values",
+			flagVariables: []string{"APPNAME=myapp", "ARGS=a=b", "EMPTY="},
+			want:          map[string]string{"APPNAME": "myapp", "ARGS": "a=b", "EMPTY": ""},
+		},
+		{
+			name:          "value read from a file",
+			flagVariables: []string{"CERT=@" + certPath},
+			want:          map[string]string{"CERT": cert},
+		},
+		{
+			name:          "escaped @",
+			flagVariables: []string{"HANDLE=@@draft"},
+			want:          map[string]string{"HANDLE": "@draft"},
+		},
+		{
+			name:          "missing file",
+			flagVariables: []string{"CERT=@" + filepath.Join(dir, "missing.pem")},
+			wantErr:       true,
+		},
+		{
+			name:          "missing =",
+			flagVariables: []string{"APPNAME"},
+			wantErr:       true,
+		},
+		{
+			name:          "missing name",
+			flagVariables: []string{"=myapp"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFlags(tt.flagVariables)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "DRAFT_VAR_APPNAME=myapp", "DRAFT_VAR_ARGS=a=b", "DRAFT_VAR_=ignored", "DRAFT_CONFIG=x"}
+	assert.Equal(t, map[string]string{"APPNAME": "myapp", "ARGS": "a=b"}, FromEnv(environ))
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "vars.yaml")
+	assert.Nil(t, os.WriteFile(yamlPath, []byte("APPNAME: myapp\nPORT: 8080\nDEBUG: true\nEMPTY:\n"), 0644))
+	got, err := ReadFile(yamlPath)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"APPNAME": "myapp", "PORT": "8080", "DEBUG": "true", "EMPTY": ""}, got)
+
+	envPath := filepath.Join(dir, "vars.env")
+	assert.Nil(t, os.WriteFile(envPath, []byte("# comment\nAPPNAME=myapp\nexport PORT=\"8080\"\n"), 0644))
+	got, err = ReadFile(envPath)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"APPNAME": "myapp", "PORT": "8080"}, got)
+
+	nestedPath := filepath.Join(dir, "nested.yaml")
+	assert.Nil(t, os.WriteFile(nestedPath, []byte("APPNAME:\n  name: myapp\n"), 0644))
+	_, err = ReadFile(nestedPath)
+	assert.NotNil(t, err)
+
+	_, err = ReadFile(filepath.Join(dir, "missing.yaml"))
+	assert.NotNil(t, err)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "vars.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("APPNAME: fromfile\nPORT: \"80\"\nNAMESPACE: fromfile\n"), 0644))
+
+	t.Setenv("DRAFT_VAR_APPNAME", "fromenv")
+	t.Setenv("DRAFT_VAR_PORT", "8080")
+
+	got, err := Load([]string{"APPNAME=fromflag"}, filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "fromflag", got["APPNAME"])
+	assert.Equal(t, "8080", got["PORT"])
+	assert.Equal(t, "fromfile", got["NAMESPACE"])
+
+	_, err = Load(nil, filepath.Join(dir, "missing.yaml"))
+	assert.NotNil(t, err)
+}
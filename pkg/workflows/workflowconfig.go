@@ -1,5 +1,19 @@
 package workflows
 
+import (
+	"path"
+
+	"github.com/Azure/draft/pkg/backends"
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// the variables of the workflows that set the helm chart or kustomize overlay they deploy
+const (
+	ChartPathVariable         = "CHARTPATH"
+	ChartOverridePathVariable = "CHARTOVERRIDEPATH"
+	KustomizePathVariable     = "KUSTOMIZEPATH"
+)
+
 type WorkflowConfig struct {
 	AcrName           string
 	ContainerName     string
@@ -7,6 +21,8 @@ type WorkflowConfig struct {
 	AksClusterName    string
 	BranchName        string
 	BuildContextPath  string
+	KustomizeOverlay  string
+	HelmChart         string
 }
 
 func (config *WorkflowConfig) SetFlagValuesToMap() map[string]string {
@@ -35,5 +51,31 @@ func (config *WorkflowConfig) SetFlagValuesToMap() map[string]string {
 		flagValuesMap["BUILDCONTEXTPATH"] = config.BuildContextPath
 	}
 
+	if config.KustomizeOverlay != "" {
+		flagValuesMap[KustomizePathVariable] = "./" + path.Join(consts.KustomizeOverlaysDir, config.KustomizeOverlay)
+	}
+
+	if config.HelmChart != "" {
+		setHelmChartPaths(flagValuesMap, config.HelmChart)
+	}
+
 	return flagValuesMap
 }
+
+// setHelmChartPaths sets the chart and production values paths of the workflow to the chart at chartPath,
+// relative to the project directory
+func setHelmChartPaths(flagValuesMap map[string]string, chartPath string) {
+	flagValuesMap[ChartPathVariable] = "./" + path.Clean(chartPath)
+	if flagValuesMap[ChartOverridePathVariable] == "" {
+		flagValuesMap[ChartOverridePathVariable] = "./" + path.Join(chartPath, "production.yaml")
+	}
+}
+
+// workflowTarget returns the chart or overlay the workflow deploys, read from its variables
+func workflowTarget(variables map[string]string) backends.Target {
+	return backends.Target{
+		HelmChart:        variables[ChartPathVariable],
+		HelmValuesFile:   variables[ChartOverridePathVariable],
+		KustomizeOverlay: variables[KustomizePathVariable],
+	}
+}
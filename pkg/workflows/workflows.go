@@ -5,20 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
-	"os"
 	"path"
-	"strings"
 
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
-	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/cli-runtime/pkg/printers"
-	"k8s.io/client-go/kubernetes/scheme"
 
-	"github.com/manifoldco/promptui"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/embedutils"
 	"github.com/Azure/draft/pkg/osutil"
@@ -39,33 +32,13 @@ type Workflows struct {
 	workflowTemplates fs.FS
 }
 
-func CreateWorkflows(dest string, deployType string, flagVariables []string, templateWriter templatewriter.TemplateWriter, flagValuesMap map[string]string) error {
+// CreateWorkflows generates the workflow for the deploy type, using flagValuesMap for the variables passed to the command
+func CreateWorkflows(dest string, deployType string, templateWriter templatewriter.TemplateWriter, flagValuesMap map[string]string) error {
 	if flagValuesMap == nil {
 		return fmt.Errorf("flagValuesMap is nil")
 	}
-	var err error
-	for _, flagVar := range flagVariables {
-		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
-		if !ok {
-			return fmt.Errorf("invalid variable format: %s", flagVar)
-		}
-		flagValuesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
-	}
-
-	if deployType == "" {
-		selection := &promptui.Select{
-			Label: "Select k8s Deployment Type",
-			Items: []string{"helm", "kustomize", "manifests"},
-		}
-
-		_, deployType, err = selection.Run()
-		if err != nil {
-			return err
-		}
-	}
 
-	workflow := createWorkflowsFromEmbedFS(template.Workflows, dest)
+	workflow := CreateWorkflowsFromEmbedFS(template.Workflows, dest)
 	workflowConfig, ok := workflow.configs[deployType]
 	if !ok {
 		return errors.New("invalid deployment type")
@@ -77,83 +50,34 @@ func CreateWorkflows(dest string, deployType string, flagVariables []string, tem
 
 	maps.Copy(customInputs, flagValuesMap)
 
-	if err = updateProductionDeployments(deployType, dest, customInputs, templateWriter); err != nil {
+	backend, err := backends.Get(deployType)
+	if err != nil {
+		return err
+	}
+	if err = updateProductionDeployments(backend, dest, customInputs, templateWriter); err != nil {
 		return err
 	}
 	return workflow.createWorkflowFiles(deployType, customInputs, templateWriter)
 }
 
-func updateProductionDeployments(deployType, dest string, flagValuesMap map[string]string, templateWriter templatewriter.TemplateWriter) error {
+// updateProductionDeployments sets the image of the production deployment to the image the workflow pushes
+func updateProductionDeployments(backend backends.DeploymentBackend, dest string, flagValuesMap map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	productionImage := fmt.Sprintf("%s.azurecr.io/%s", flagValuesMap["AZURECONTAINERREGISTRY"], flagValuesMap["CONTAINERNAME"])
-	switch deployType {
-	case "helm":
-		return setHelmContainerImage(dest+"/charts/production.yaml", productionImage, templateWriter)
-	case "kustomize":
-		return setDeploymentContainerImage(dest+"/overlays/production/deployment.yaml", productionImage)
-	case "manifests":
-		return setDeploymentContainerImage(dest+"/manifests/deployment.yaml", productionImage)
-	}
-	return nil
+	return backend.SetImage(dest, workflowTarget(flagValuesMap), productionImage, templateWriter)
 }
 
-func setDeploymentContainerImage(filePath, productionImage string) error {
-
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	file, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	k8sObj, _, err := decode(file, nil, nil)
-	if err != nil {
-		return err
-	}
-	deploy, ok := k8sObj.(*appsv1.Deployment)
-	if !ok {
-		return errors.New("could not decode kubernetes deployment")
-	}
-
-	if len(deploy.Spec.Template.Spec.Containers) != 1 {
-		return errors.New("unsupported number of containers defined in the deployment spec")
-	}
-
-	deploy.Spec.Template.Spec.Containers[0].Image = productionImage
-
-	printer := printers.YAMLPrinter{}
-
-	out, err := os.OpenFile(filePath, os.O_RDWR, 0755)
-	if err != nil {
-		return nil
-	}
-	defer func() {
-		if err := out.Close(); err != nil {
-			log.Errorf("error closing file: %v", err)
-		}
-	}()
-
-	return printer.PrintObj(deploy, out)
+// DeployTypes returns a slice of the deployment types with a workflow
+func (w *Workflows) DeployTypes() []string {
+	return maps.Keys(w.workflows)
 }
 
-func setHelmContainerImage(filePath, productionImage string, templateWriter templatewriter.TemplateWriter) error {
-	file, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var deploy HelmProductionYaml
-	err = yaml.Unmarshal(file, &deploy)
-	if err != nil {
-		return err
-	}
-
-	deploy.Image.Repository = productionImage
-
-	out, err := yaml.Marshal(deploy)
-	if err != nil {
-		return err
+// GetConfig returns the draft config of the workflow for deployType
+func (w *Workflows) GetConfig(deployType string) (*config.DraftConfig, error) {
+	val, ok := w.configs[deployType]
+	if !ok {
+		return nil, fmt.Errorf("deployment type: %s is not currently supported", deployType)
 	}
-
-	return templateWriter.WriteFile(filePath, out)
+	return val, nil
 }
 
 func (w *Workflows) loadConfig(deployType string) (*config.DraftConfig, error) {
@@ -169,14 +93,14 @@ func (w *Workflows) loadConfig(deployType string) (*config.DraftConfig, error) {
 	}
 
 	var draftConfig config.DraftConfig
-	if err = yaml.Unmarshal(configBytes, &draftConfig); err != nil {
-		return nil, err
+	if err = config.DecodeStrict(configBytes, &draftConfig); err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
 	}
 
 	return &draftConfig, nil
 }
 
-func createWorkflowsFromEmbedFS(workflowTemplates embed.FS, dest string) *Workflows {
+func CreateWorkflowsFromEmbedFS(workflowTemplates embed.FS, dest string) *Workflows {
 	deployMap, err := embedutils.EmbedFStoMap(workflowTemplates, parentDirName)
 	if err != nil {
 		log.Fatal(err)
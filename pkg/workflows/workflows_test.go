@@ -1,20 +1,16 @@
 package workflows
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"io/ioutil"
 	"os"
 	"testing"
 	"testing/fstest"
 
-	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/client-go/kubernetes/scheme"
-
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Azure/draft/pkg/backends"
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/embedutils"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
@@ -24,7 +20,6 @@ import (
 func TestCreateWorkflows(t *testing.T) {
 	dest := "."
 	deployType := "helm"
-	flagVariables := []string{}
 	templatewriter := &writers.LocalFSWriter{}
 	flagValuesMap := map[string]string{"AZURECONTAINERREGISTRY": "testAcr", "CONTAINERNAME": "testContainer", "RESOURCEGROUP": "testRG", "CLUSTERNAME": "testCluster", "BRANCHNAME": "testBranch", "BUILDCONTEXTPATH": "."}
 	flagValuesMapNoRoot := map[string]string{"AZURECONTAINERREGISTRY": "testAcr", "CONTAINERNAME": "testContainer", "RESOURCEGROUP": "testRG", "CLUSTERNAME": "testCluster", "BRANCHNAME": "testBranch", "BUILDCONTEXTPATH": "test"}
@@ -48,8 +43,8 @@ func TestCreateWorkflows(t *testing.T) {
 			tempFileName: "charts/production.yaml",
 			tempPath:     "../../test/templates/helm/charts/production.yaml",
 			cleanUp: func() {
-				os.Remove(".charts")
-				os.Remove(".github")
+				os.RemoveAll("charts")
+				os.RemoveAll(".github")
 			},
 		},
 		{
@@ -61,8 +56,8 @@ func TestCreateWorkflows(t *testing.T) {
 			tempFileName: "overlays/production/deployment.yaml",
 			tempPath:     "../../test/templates/kustomize/overlays/production/deployment.yaml",
 			cleanUp: func() {
-				os.Remove(".overlays")
-				os.Remove(".github")
+				os.RemoveAll("overlays")
+				os.RemoveAll(".github")
 			},
 		}, {
 			name:         "manifests",
@@ -73,8 +68,8 @@ func TestCreateWorkflows(t *testing.T) {
 			tempFileName: "manifests/deployment.yaml",
 			tempPath:     "../../test/templates/manifests/manifests/deployment.yaml",
 			cleanUp: func() {
-				os.Remove(".manifests")
-				os.Remove(".github")
+				os.RemoveAll("manifests")
+				os.RemoveAll(".github")
 			},
 		},
 		{
@@ -86,8 +81,8 @@ func TestCreateWorkflows(t *testing.T) {
 			tempFileName: "manifests/deployment.yaml",
 			tempPath:     "../../test/templates/manifests/manifests/deployment.yaml",
 			cleanUp: func() {
-				os.Remove(".manifests")
-				os.Remove(".github")
+				os.RemoveAll("manifests")
+				os.RemoveAll(".github")
 			},
 		},
 		{
@@ -99,6 +94,8 @@ func TestCreateWorkflows(t *testing.T) {
 			tempFileName: "manifests/deployment.yaml",
 			tempPath:     "../../test/templates/manifests/manifests/deployment.yaml",
 			cleanUp: func() {
+				os.RemoveAll("charts")
+				os.RemoveAll(".github")
 			},
 		},
 	}
@@ -108,11 +105,11 @@ func TestCreateWorkflows(t *testing.T) {
 		err := createTempDeploymentFile("charts", "charts/production.yaml", "../../test/templates/helm/charts/production.yaml")
 		assert.Nil(t, err)
 
-		err = CreateWorkflows(dest, deployType, flagVariables, templatewriter, flagValuesMap)
+		err = CreateWorkflows(dest, deployType, templatewriter, flagValuesMap)
 		if err != nil {
 			t.Errorf("Default Build Context CreateWorkflows() error = %v, wantErr %v", err, tt.shouldError)
 		}
-		err = CreateWorkflows(dest, deployType, flagVariables, templatewriter, flagValuesMapNoRoot)
+		err = CreateWorkflows(dest, deployType, templatewriter, flagValuesMapNoRoot)
 		if err != nil {
 			t.Errorf("Custom Build Context CreateWorkflows() error = %v, wantErr %v", err, tt.shouldError)
 		}
@@ -121,74 +118,14 @@ func TestCreateWorkflows(t *testing.T) {
 	}
 }
 
-func TestUpdateProductionDeploymentsValid(t *testing.T) {
-	testTemplateWriter := &writers.LocalFSWriter{}
-
-	//test for valid helm deployment file
-	helmFileName, _ := createTempManifest("../../test/templates/helm_prod_values.yaml")
-	defer os.Remove(helmFileName)
-
-	assert.Nil(t, setHelmContainerImage(helmFileName, "testImage", testTemplateWriter))
-
-	helmDeploy := &HelmProductionYaml{}
-	assert.Nil(t, helmDeploy.LoadFromFile(helmFileName))
-	assert.Equal(t, "testImage", helmDeploy.Image.Repository)
-
-	//test for valid deployment file
-	deploymentFileName, _ := createTempManifest("../../test/templates/deployment.yaml")
-	defer os.Remove(deploymentFileName)
-
-	assert.Nil(t, setDeploymentContainerImage(deploymentFileName, "testImage"))
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	file, err := ioutil.ReadFile(deploymentFileName)
-	assert.Nil(t, err)
-
-	k8sObj, _, err := decode(file, nil, nil)
-	assert.Nil(t, err)
-
-	deploy, ok := k8sObj.(*appsv1.Deployment)
-	assert.True(t, ok)
-	assert.Equal(t, "testImage", deploy.Spec.Template.Spec.Containers[0].Image)
-}
-
-func TestUpdateProductionDeploymentsInvalid(t *testing.T) {
-	testTemplateWriter := &writers.LocalFSWriter{}
-
-	//test for invalid helm deployment file
-	tempFile, err := ioutil.TempFile("", "*.yaml")
-	assert.Nil(t, err)
-	defer os.Remove(tempFile.Name())
-	yamlData := []byte(`not a valid yaml`)
-	_, err = tempFile.Write(yamlData)
-	assert.Nil(t, err)
-	err = tempFile.Close()
-	assert.Nil(t, err)
-	assert.NotNil(t, setHelmContainerImage(tempFile.Name(), "testImage", testTemplateWriter))
-
-	//test for invalid deployment file
-	assert.NotNil(t, setDeploymentContainerImage(tempFile.Name(), "testImage"))
-
-	//test for invalid k8sObj
-	invalidDeploymentFile, _ := createTempManifest("../../test/templates/invalid_deployment.yaml")
-	assert.Equal(t, errors.New("could not decode kubernetes deployment"), setDeploymentContainerImage(invalidDeploymentFile, "testImage"))
-
-	//test for unsupported number of containers in the deployment spec
-	invalidDeploymentFile, _ = createTempManifest("../../test/templates/unsupported_no_of_containers.yaml")
-	defer os.Remove(invalidDeploymentFile)
-	assert.Equal(t, errors.New("unsupported number of containers defined in the deployment spec"), setDeploymentContainerImage(invalidDeploymentFile, "testImage"))
-}
-
 func TestUpdateProductionDeploymentsMissing(t *testing.T) {
 	flagValuesMap := map[string]string{"AZURECONTAINERREGISTRY": "testRegistry", "CONTAINERNAME": "testContainer"}
 	testTemplateWriter := &writers.LocalFSWriter{}
-	//test for missing deploy type
-	assert.Nil(t, updateProductionDeployments("", ".", flagValuesMap, testTemplateWriter))
-
 	//test for missing helm deployment file
-	assert.NotNil(t, setHelmContainerImage("", "testImage", testTemplateWriter))
+	assert.NotNil(t, updateProductionDeployments(&backends.Helm{}, t.TempDir(), flagValuesMap, testTemplateWriter))
 
 	//test for missing deployment file
-	assert.NotNil(t, setDeploymentContainerImage("", "testImage"))
+	assert.NotNil(t, updateProductionDeployments(&backends.Manifests{}, t.TempDir(), flagValuesMap, testTemplateWriter))
 }
 
 func TestLoadConfig(t *testing.T) {
@@ -269,27 +206,6 @@ type loadConfTestCase struct {
 	isNil      bool
 }
 
-func createTempManifest(path string) (string, error) {
-	file, err := ioutil.TempFile("", "*.yaml")
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	var source *os.File
-	source, err = os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer source.Close()
-
-	_, err = io.Copy(file, source)
-	if err != nil {
-		return "", err
-	}
-	return file.Name(), nil
-}
-
 func createTempDeploymentFile(dirPath, fileName, path string) error {
 	err := os.MkdirAll(dirPath, 0755)
 	if err != nil {
@@ -396,3 +312,21 @@ func fsToMap(fsFS fs.FS, path string) (map[string]fs.DirEntry, error) {
 
 	return mapping, nil
 }
+
+func TestWorkflowTarget(t *testing.T) {
+	config := &WorkflowConfig{HelmChart: "charts/web/", KustomizeOverlay: "staging"}
+	flagValuesMap := config.SetFlagValuesToMap()
+	assert.Equal(t, map[string]string{
+		ChartPathVariable:         "./charts/web",
+		ChartOverridePathVariable: "./charts/web/production.yaml",
+		KustomizePathVariable:     "./overlays/staging",
+	}, flagValuesMap)
+	assert.Equal(t, backends.Target{
+		HelmChart:        "./charts/web",
+		HelmValuesFile:   "./charts/web/production.yaml",
+		KustomizeOverlay: "./overlays/staging",
+	}, workflowTarget(flagValuesMap))
+
+	assert.Empty(t, (&WorkflowConfig{}).SetFlagValuesToMap())
+	assert.Equal(t, backends.Target{}, workflowTarget(map[string]string{}))
+}
@@ -1,4 +1,4 @@
 $filesExist=$true
-$filesExist=$filesExist -and (Test-Path -Path ./charts/templates/ingress.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/templates/ingress.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
 if (-not $filesExist) {Exit 1}
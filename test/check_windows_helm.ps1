@@ -1,18 +1,20 @@
 $filesExist=$true
-$filesExist=$filesExist -and (Test-Path -Path ./charts/Chart.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/Chart.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/production.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/production.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/.helmignore -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/.helmignore -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/templates/deployment.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/templates/deployment.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/templates/service.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/templates/service.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/templates/namespace.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/templates/namespace.yaml -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/templates/_helpers.tpl -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/templates/_helpers.tpl -PathType Leaf)
 echo "$file exists: $filesExist"
-$filesExist=$filesExist -and (Test-Path -Path ./charts/values.yaml -PathType Leaf)
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/values.yaml -PathType Leaf)
+echo "$file exists: $filesExist"
+$filesExist=$filesExist -and (Test-Path -Path ./charts/testapp/values.schema.json -PathType Leaf)
 echo "$file exists: $filesExist"
 if (-not $filesExist) {Exit 1}
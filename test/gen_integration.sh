@@ -127,6 +127,10 @@ deployVariables:
     value: \"testapp\"
   - name: \"IMAGENAME\"
     value: \"$imagename\"
+  - name: \"RUNASNONROOT\"
+    value: \"false\"
+  - name: \"RUNASUSER\"
+    value: \"0\"
 languageVariables:
   - name: \"VERSION\"
     value: \"$version\"
@@ -148,6 +152,10 @@ deployVariables:
     value: \"testapp\"
   - name: \"IMAGENAME\"
     value: \"$imagename\"
+  - name: \"RUNASNONROOT\"
+    value: \"false\"
+  - name: \"RUNASUSER\"
+    value: \"0\"
 languageVariables:
   - name: \"VERSION\"
     value: \"$version\"
@@ -169,6 +177,10 @@ deployVariables:
     value: \"testapp\"
   - name: \"IMAGENAME\"
     value: \"$imagename\"
+  - name: \"RUNASNONROOT\"
+    value: \"false\"
+  - name: \"RUNASUSER\"
+    value: \"0\"
 languageVariables:
   - name: \"VERSION\"
     value: \"$version\"
@@ -177,6 +189,11 @@ languageVariables:
   - name: \"PORT\"
     value: \"$port\"" > ./integration/$lang/manifest.yaml
 
+    # languages without a sample repo only get config fixtures, which are rendered by the unit tests
+    if [ "$repo" == "null" ]; then
+        continue
+    fi
+
     # create helm workflow
     helm_create_update_job_name=$lang-helm-create-update
     echo $helm_create_update_job_name >> $helm_workflow_names_file
@@ -253,8 +270,8 @@ languageVariables:
         uses: azure/k8s-bake@v2.2
         with:
           renderEngine: 'helm'
-          helmChart: ./langtest/charts
-          overrideFiles: ./langtest/charts/values.yaml
+          helmChart: ./langtest/charts/testapp
+          overrideFiles: ./langtest/charts/testapp/values.yaml
           overrides: |
             replicas:2
           helm-version: 'latest'
@@ -706,7 +723,7 @@ languageVariables:
         with:
           name: $lang-helm-create
           path: ./langtest/
-      - run: Remove-Item ./langtest/charts/templates/ingress.yaml -Recurse -Force -ErrorAction Ignore
+      - run: Remove-Item ./langtest/charts/testapp/templates/ingress.yaml -Recurse -Force -ErrorAction Ignore
       - run: ./draft.exe -v update -d ./langtest/ $ingress_test_args
       - uses: actions/download-artifact@v3
         with:
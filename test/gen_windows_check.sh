@@ -3,7 +3,7 @@ deployDir="./deployTypes"
 
 deployTypes=("helm" "kustomize")
 
-ignoredFiles=("./draft.yaml" "./skaffold.yaml" "./charts/templates/helpers.tpl" "./charts/helmignore")
+ignoredFiles=("./draft.yaml" "./skaffold.yaml" "./charts/testapp/templates/helpers.tpl" "./charts/testapp/helmignore")
 let count=0
 for deploy in ${deployTypes[@]};do
     scriptName=./test/check_windows_$deploy.ps1